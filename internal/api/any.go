@@ -5,11 +5,10 @@
 package api
 
 import (
-	"cosmoparrot/internal/cache"
 	"cosmoparrot/internal/config"
 	"cosmoparrot/internal/utils"
 	"encoding/json"
-	"io"
+	"errors"
 	"math/rand"
 	"slices"
 	"strconv"
@@ -18,8 +17,8 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/log"
-	go_cache "github.com/patrickmn/go-cache"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const maxResponseDelayMs = 60000
@@ -38,77 +37,128 @@ func newRandomString(length int) string {
 	return string(b)
 }
 
+// isWebUIRequest reports whether a browser asks for the web UI, which is served
+// by the static file handler instead of the echo handler.
+func isWebUIRequest(c *fiber.Ctx) bool {
+	return c.Path() == "/" && utils.IsBrowser(c.Get("User-Agent"))
+}
+
 func handleAnyRequest(c *fiber.Ctx) error {
-	userAgent := c.Get("User-Agent")
-	if c.Path() == "/" && utils.IsBrowser(userAgent) {
+	if isWebUIRequest(c) {
 		return c.Next()
 	}
 
+	// no-op unless tracing is enabled
+	span := trace.SpanFromContext(c.UserContext())
+
+	validation, _ := c.Locals(tokenValidationKey).(*tokenValidation)
+	signatureValid, verified := c.Locals(signatureValidKey).(bool)
+	rejectionCode := 0
+	if validation != nil && !validation.Valid {
+		rejectionCode = validation.status
+	} else if verified && !signatureValid {
+		rejectionCode = config.LoadedConfiguration.SignatureFailureResponseCode
+	}
+
 	// The request body is only read when it is echoed back.
 	var responseBody json.RawMessage
 
-	if getMirrorBody(c) {
+	mirrorBody := getMirrorBody(c)
+	bodySize := max(c.Request().Header.ContentLength(), 0)
+	if mirrorBody {
 		if body := c.Body(); len(body) > 0 {
 			if !json.Valid(body) {
-				log.Debug("failed to deserialize request body: invalid JSON")
+				log.Debug().Msg("failed to deserialize request body: invalid JSON")
+				// like a protected consumer, reject unauthenticated requests first
+				if rejectionCode != 0 {
+					return c.SendStatus(rejectionCode)
+				}
 				return c.SendStatus(fiber.StatusBadRequest)
 			}
 			responseBody = body
+			bodySize = len(body)
 		}
-	} else if stream := c.Context().RequestBodyStream(); stream != nil {
+	} else {
 		// drain the streamed body so the connection can be reused
-		_, _ = io.Copy(io.Discard, stream)
+		n, err := drainBody(c)
+		if errors.Is(err, errBodyTooLarge) {
+			return rejectBody(c)
+		}
+		bodySize = int(n)
 	}
+	span.SetAttributes(
+		attrRequestBodyMirrored.Bool(mirrorBody),
+		attrRequestBodySize.Int(bodySize),
+	)
 
 	setResponseHeaders(c)
 
-	reqData := &request{
-		Time:    time.Now(),
-		Path:    c.Path(),
-		Method:  c.Method(),
-		Headers: c.GetReqHeaders(),
-		Body:    responseBody,
+	reqData := newRequestRecord(c)
+	reqData.Body = responseBody
+	consumer := requestConsumer(c)
+	if consumer != nil {
+		reqData.Consumer = consumer.Name
+	}
+	reqData.TokenValidation = validation
+	if verified {
+		reqData.SignatureValid = &signatureValid
 	}
 
 	// write request to store if request key is found
-	// in the request headers
-	if key := extractStoreKey(c); key != "" {
-		log.Debugf("writing to cache with key %s", key)
-
-		var requests []*request
-		if entry, found := cache.Current.Get(key); found {
-			err := json.Unmarshal([]byte(entry.(string)), &requests)
-			if err != nil {
-				log.Errorf("failed to deserialize data, error: %s", err.Error())
-				return c.SendStatus(fiber.StatusInternalServerError)
-			}
-		} else {
-			requests = []*request{}
-		}
-
-		requests = append(requests, reqData)
+	// in the request headers, consumers always have one
+	key := extractStoreKey(c)
+	if key == "" && consumer != nil {
+		key = consumer.StoreKey
+	}
+	if key != "" {
+		log.Debug().Str("key", key).Msg("writing to cache")
+		span.SetAttributes(attrStoreKey.String(key))
 
-		jsonData, err := json.Marshal(requests)
+		stored, err := storeRequest(key, reqData)
 		if err != nil {
-			log.Errorf("failed to serialize data, error: %s", err.Error())
-
 			return c.SendStatus(fiber.StatusInternalServerError)
 		}
-
-		// write to store
-		cache.Current.Set(key, string(jsonData), go_cache.DefaultExpiration)
+		span.AddEvent("store write", trace.WithAttributes(attrStoreRequests.Int(stored)))
 	}
 
-	if delay := getResponseDelay(c); delay > 0 {
+	delay := getResponseDelay(c)
+	if delay > 0 {
+		span.AddEvent("response delay start")
 		time.Sleep(delay)
+		span.AddEvent("response delay end")
 	}
 
-	if size := getResponseSize(c); size > 0 {
+	size := getResponseSize(c)
+	if size > 0 {
 		offset := int(paddingOffset.Add(1) % maxResponseSizePaddingWindowSize)
 		reqData.Padding = paddingSource[offset : offset+size]
 	}
 
-	return c.Status(getResponseCode(c)).JSON(reqData)
+	code := getResponseCode(c)
+	// faults do not override an explicit response code
+	if _, explicit := queryResponseCode(c); !explicit && consumer != nil && consumer.fault() {
+		code = consumer.FaultResponseCode
+	}
+	if rejectionCode != 0 {
+		code = rejectionCode
+	}
+	span.SetAttributes(
+		attrResponseCode.Int(code),
+		attrResponseDelay.Int64(delay.Milliseconds()),
+		attrResponseSize.Int(size),
+	)
+
+	if chaos := getChaos(c); chaos != "" {
+		span.SetAttributes(attrChaos.String(chaos))
+		body, err := json.Marshal(reqData)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to serialize data")
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		return injectChaos(c, chaos, code, body)
+	}
+
+	return c.Status(code).JSON(reqData)
 }
 
 func extractStoreKey(c *fiber.Ctx) string {
@@ -174,12 +224,36 @@ func getMirrorBody(c *fiber.Ctx) bool {
 	return enabled
 }
 
+// queryResponseCode reads the "responseCode" query parameter. Reports false for
+// missing or invalid values.
+func queryResponseCode(c *fiber.Ctx) (int, bool) {
+	rc := queryCaseInsensitive(c, "responseCode")
+	if rc == "" {
+		return 0, false
+	}
+	code, err := strconv.Atoi(strings.TrimSpace(rc))
+	if err != nil || code < 100 || code > 599 {
+		return 0, false
+	}
+	return code, true
+}
+
 func getResponseCode(c *fiber.Ctx) int {
-	if rc := queryCaseInsensitive(c, "responseCode"); rc != "" {
-		if code, err := strconv.Atoi(strings.TrimSpace(rc)); err == nil && code >= 100 && code <= 599 {
+	if code, ok := queryResponseCode(c); ok {
+		return code
+	}
+
+	if p := requestConsumer(c); p != nil {
+		if code, ok := p.MethodResponseCodes[c.Method()]; ok {
 			return code
 		}
-		// invalid override -> fallthrough to mapping/default
+		if p.ResponseCode != 0 {
+			return p.ResponseCode
+		}
+	}
+
+	if l := requestListener(c); l != nil && l.ResponseCode != 0 {
+		return l.ResponseCode
 	}
 
 	if code, ok := config.LoadedConfiguration.MethodResponseCodeMap[c.Method()]; ok {
@@ -190,11 +264,15 @@ func getResponseCode(c *fiber.Ctx) int {
 }
 
 // getResponseDelay reads the optional "responseDelay" query parameter (milliseconds)
-// and returns the corresponding time.Duration. Returns 0 for missing, non-integer,
-// negative, or out-of-range (>60000 ms) values.
+// and returns the corresponding time.Duration. Returns 0 for non-integer,
+// negative, or out-of-range (>60000 ms) values. Missing values fall back to the
+// delay profile of the consumer, if any.
 func getResponseDelay(c *fiber.Ctx) time.Duration {
 	raw := queryCaseInsensitive(c, "responseDelay")
 	if raw == "" {
+		if p := requestConsumer(c); p != nil {
+			return p.delay()
+		}
 		return 0
 	}
 
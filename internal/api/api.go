@@ -6,44 +6,99 @@ package api
 
 import (
 	"cosmoparrot/internal/config"
+	"crypto/tls"
 	"embed"
 	"fmt"
 	"net/http"
 
-	"github.com/gofiber/contrib/otelfiber/v2"
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/log"
 	"github.com/gofiber/fiber/v2/middleware/filesystem"
 	"github.com/gofiber/fiber/v2/middleware/healthcheck"
+	"github.com/rs/zerolog/log"
 )
 
 func NewApp(f embed.FS) *fiber.App {
-	app := fiber.New(fiber.Config{
-		StreamRequestBody: true,
-	})
-	var shutdownTracing func()
-	apiMiddleware := make([]fiber.Handler, 0)
-	if config.LoadedConfiguration.OTelEnabled {
-		shutdownTracing = initTracerProvider()
-		apiMiddleware = append(apiMiddleware, otelfiber.Middleware())
+	app, _ := newApp(f)
+	return app
+}
+
+// newApp builds the app along with the TLS setup of the TLS listeners, which
+// is nil unless any of them is enabled.
+func newApp(f embed.FS) (*fiber.App, *serverTLS) {
+	app := fiber.New(newServerConfig())
+	cfg := config.LoadedConfiguration
+	var tlsSetup *serverTLS
+	if cfg.TLSEnabled || tlsScenariosEnabled() {
+		var err error
+		if tlsSetup, err = newServerTLS(); err != nil {
+			log.Fatal().Err(err).Msg("failed to set up TLS")
+		}
+	}
+	var shutdownTelemetry func()
+	tracingMiddleware := make([]fiber.Handler, 0)
+	if cfg.OTelEnabled {
+		shutdownTelemetry = initTelemetry()
+		tracingMiddleware = append(tracingMiddleware, newTracingMiddleware()...)
+	}
+	// The same request metrics back both the metrics endpoint and the OTLP export.
+	if cfg.MetricsEnabled || (cfg.OTelEnabled && cfg.OTelMetricsEnabled) {
+		app.Use(createMetricsHandler())
+	}
+	if cfg.MetricsEnabled {
+		app.Get(metricsPath, handleGetMetrics())
 	}
 	app.Use(createNewLogHandler())
 	app.Use(healthcheck.New())
+	app.Use(handleBodyLimit)
 	app.Hooks().OnShutdown(func() error {
-		if shutdownTracing != nil {
-			shutdownTracing()
+		if shutdownTelemetry != nil {
+			shutdownTelemetry()
 		}
 		return nil
 	})
 
-	// Attach tracing only on /api routes to avoid exporting health/static traffic.
-	api := app.Group("/api", apiMiddleware...)
+	if cfg.OAuthEnabled {
+		issuer, err := newTokenIssuer()
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to set up the OAuth2 token endpoint")
+		}
+		app.Post(oauthTokenPath, bufferBody, issuer.handleToken)
+		app.Get(oauthJwksPath, issuer.handleGetJwks)
+	}
+
+	// Attach tracing only on /api and echo routes to avoid exporting health/static traffic.
+	api := app.Group("/api", tracingMiddleware...)
 	v1 := api.Group("/v1")
-	v1.Get("/requests", handleGetAllRequests)
-	v1.Get("/requests/:key", handleGetRequestByKey)
+	authenticated := newAuthHandler()
+	v1.Get("/requests", authenticated, handleGetAllRequests)
+	v1.Get("/requests/:key", authenticated, handleGetRequestByKey)
+	v1.Get("/traces/:traceId/requests", authenticated, handleGetRequestsByTraceId)
+	v1.Get("/consumers", authenticated, handleGetConsumers)
+	v1.Get("/consumers/:name", authenticated, handleGetConsumer)
+	v1.Put("/consumers/:name", authenticated, bufferBody, handlePutConsumer)
+	v1.Delete("/consumers/:name", authenticated, handleDeleteConsumer)
+	v1.Get("/consumers/:name/requests", authenticated, handleGetConsumerRequests)
 	v1.Get("/slowloris", handleGetSlowloris)
+	v1.Get("/sse", handleGetSSE)
+	v1.Get("/ws", handleWebsocketUpgrade, websocket.New(handleWebsocket))
+	v1.Post("/ws/:key", authenticated, bufferBody, handleSendWebsocketMessage)
+	if tlsSetup != nil {
+		v1.Get("/tls/ca.pem", tlsSetup.handleGetCA)
+	}
 	v1.All("/devnull", handleDevNull)
 
+	for _, handler := range tracingMiddleware {
+		app.Use(handler)
+	}
+	app.Use(bufferEchoBody)
+	if cfg.EchoJwtEnabled {
+		app.Use(newTokenValidationHandler())
+	}
+	if cfg.SignatureEnabled {
+		app.Use(newSignatureVerificationHandler())
+	}
+	app.Use(handleConsumer)
 	app.Use(handleAnyRequest)
 
 	app.Use("/", filesystem.New(filesystem.Config{
@@ -51,10 +106,73 @@ func NewApp(f embed.FS) *fiber.App {
 		PathPrefix: "web",
 	}))
 
-	return app
+	return app, tlsSetup
 }
 
+// newServerConfig applies the configured server limits so that different
+// consumer server profiles can be emulated.
+func newServerConfig() fiber.Config {
+	cfg := config.LoadedConfiguration
+	return fiber.Config{
+		// the listener is logged through the structured logger instead
+		DisableStartupMessage: true,
+		StreamRequestBody:     true,
+		ReadBufferSize:        cfg.ReadBufferSize,
+		WriteBufferSize:       cfg.WriteBufferSize,
+		BodyLimit:             cfg.BodyLimit,
+		ReadTimeout:           cfg.ReadTimeout,
+		WriteTimeout:          cfg.WriteTimeout,
+		IdleTimeout:           cfg.IdleTimeout,
+		Concurrency:           cfg.Concurrency,
+		DisableKeepalive:      cfg.DisableKeepalive,
+	}
+}
+
+// Listen serves HTTP and, if enabled, HTTPS, the misbehaving TLS listeners, the
+// HTTP/2 listeners, gRPC and the additional listeners, each on its own port or
+// socket at the same time.
 func Listen(f embed.FS) {
-	app := NewApp(f)
-	log.Fatal(app.Listen(fmt.Sprintf(":%d", config.LoadedConfiguration.Port)))
+	app, tlsSetup := newApp(f)
+	if tlsSetup != nil {
+		if config.LoadedConfiguration.TLSEnabled {
+			listenTLS(app, config.LoadedConfiguration.TLSPort, tlsSetup.config, "")
+		}
+		for _, scenario := range tlsSetup.scenarios {
+			listenTLS(app, scenario.port, scenario.config, scenario.name)
+		}
+	}
+	if port := config.LoadedConfiguration.HTTP2Port; port != 0 {
+		listenHTTP2(app, port, nil)
+	}
+	if port := config.LoadedConfiguration.HTTP2TLSPort; port != 0 && tlsSetup != nil {
+		listenHTTP2(app, port, tlsSetup.config)
+	}
+	if port := config.LoadedConfiguration.GRPCPort; port != 0 {
+		listenGRPC(port)
+	}
+	listenConfigured(app)
+
+	addr := fmt.Sprintf(":%d", config.LoadedConfiguration.Port)
+	log.Info().Str("addr", addr).Msg("listening")
+	if err := app.Listen(addr); err != nil {
+		log.Fatal().Err(err).Msg("failed to listen")
+	}
+}
+
+func listenTLS(app *fiber.App, port int, tlsConfig *tls.Config, scenario string) {
+	addr := fmt.Sprintf(":%d", port)
+	ln, err := tls.Listen("tcp", addr, tlsConfig)
+	if err != nil {
+		log.Fatal().Err(err).Str("addr", addr).Msg("failed to listen for TLS")
+	}
+	event := log.Info().Str("addr", addr)
+	if scenario != "" {
+		event.Str("scenario", scenario)
+	}
+	event.Msg("listening for TLS")
+	go func() {
+		if err := app.Listener(ln); err != nil {
+			log.Fatal().Err(err).Str("addr", addr).Msg("failed to listen for TLS")
+		}
+	}()
 }
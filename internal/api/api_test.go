@@ -4,10 +4,14 @@
 package api
 
 import (
+	"cosmoparrot/internal/config"
 	"embed"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -42,3 +46,73 @@ func TestHandleGetRequestByKey(t *testing.T) {
 
 	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 }
+
+func TestNewApp_ReadBufferSize(t *testing.T) {
+	original := config.LoadedConfiguration.ReadBufferSize
+	defer func() { config.LoadedConfiguration.ReadBufferSize = original }()
+
+	largeHeader := strings.Repeat("a", 8192)
+
+	config.LoadedConfiguration.ReadBufferSize = 4096
+	app := NewApp(embed.FS{})
+	req := httptest.NewRequest(http.MethodGet, "/foobar", nil)
+	req.Header.Set("X-Large", largeHeader)
+	_, err := app.Test(req)
+	// the server rejects the request before it reaches any handler
+	assert.ErrorContains(t, err, "small read buffer")
+
+	config.LoadedConfiguration.ReadBufferSize = 16384
+	app = NewApp(embed.FS{})
+	req = httptest.NewRequest(http.MethodGet, "/foobar", nil)
+	req.Header.Set("X-Large", largeHeader)
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestNewApp_DisableKeepalive(t *testing.T) {
+	original := config.LoadedConfiguration.DisableKeepalive
+	defer func() { config.LoadedConfiguration.DisableKeepalive = original }()
+
+	config.LoadedConfiguration.DisableKeepalive = true
+	app := NewApp(embed.FS{})
+
+	req := httptest.NewRequest(http.MethodGet, "/foobar", nil)
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.True(t, resp.Close, "connection should be closed after the response")
+}
+
+func TestNewApp_BodyLimit(t *testing.T) {
+	original := config.LoadedConfiguration.BodyLimit
+	defer func() { config.LoadedConfiguration.BodyLimit = original }()
+
+	config.LoadedConfiguration.BodyLimit = 16
+	app := NewApp(embed.FS{})
+	// app.Test cannot send chunked bodies
+	addr := serveApp(t, app)
+
+	tests := []struct {
+		name string
+		path string
+		body io.Reader
+		want int
+	}{
+		{"within limit", "/api/v1/devnull", strings.NewReader(strings.Repeat("a", 16)), http.StatusOK},
+		{"content length over limit", "/api/v1/devnull", strings.NewReader(strings.Repeat("a", 17)), http.StatusRequestEntityTooLarge},
+		// readers of unknown length are sent chunked
+		{"chunked within limit", "/api/v1/devnull", io.MultiReader(strings.NewReader(strings.Repeat("a", 16))), http.StatusOK},
+		{"chunked over limit", "/api/v1/devnull", io.MultiReader(strings.NewReader(strings.Repeat("a", 17))), http.StatusRequestEntityTooLarge},
+		{"chunked echo within limit", "/limit", io.MultiReader(strings.NewReader(`{"a":"0123456"}`)), http.StatusOK},
+		{"chunked echo over limit", "/limit", io.MultiReader(strings.NewReader(`{"a":"012345678"}`)), http.StatusRequestEntityTooLarge},
+		{"chunked drained echo over limit", "/limit?mirrorBody=false", io.MultiReader(strings.NewReader(strings.Repeat("a", 17))), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post("http://"+addr+tt.path, "text/plain", tt.body)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.want, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cosmoparrot/internal/auth"
+	"cosmoparrot/internal/config"
+	"crypto/subtle"
+	"encoding/base64"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+const authRealm = "cosmoparrot"
+
+// bearerToken returns the token of an "Authorization: Bearer" header.
+func bearerToken(c *fiber.Ctx) (string, bool) {
+	scheme, token, found := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+func equalSecret(given, expected string) bool {
+	return subtle.ConstantTimeCompare([]byte(given), []byte(expected)) == 1
+}
+
+// newAuthHandler protects the admin and store APIs as configured. The echo
+// handler and the endpoints that emulate consumers are never protected, so
+// the systems under test can always reach them.
+func newAuthHandler() fiber.Handler {
+	cfg := config.LoadedConfiguration
+	switch strings.ToLower(cfg.APIAuthMode) {
+	case "bearer":
+		return func(c *fiber.Ctx) error {
+			if token, ok := bearerToken(c); ok && equalSecret(token, cfg.APIAuthToken) {
+				return c.Next()
+			}
+			return unauthorized(c, `Bearer realm="`+authRealm+`"`)
+		}
+	case "basic":
+		return func(c *fiber.Ctx) error {
+			if username, password, ok := basicCredentials(c); ok &&
+				equalSecret(username, cfg.APIAuthUsername) && equalSecret(password, cfg.APIAuthPassword) {
+				return c.Next()
+			}
+			return unauthorized(c, `Basic realm="`+authRealm+`", charset="UTF-8"`)
+		}
+	case "jwt":
+		keys, err := auth.LoadKeySet(cfg.APIAuthJwksFile)
+		if err != nil {
+			// fail closed, the configuration check reports the cause up front
+			log.Error().Err(err).Msg("failed to load JWKS, rejecting all API requests")
+		}
+		verifier := &auth.Verifier{Keys: keys, Issuer: cfg.APIAuthJwtIssuer, Audience: cfg.APIAuthJwtAudience}
+		return func(c *fiber.Ctx) error {
+			token, ok := bearerToken(c)
+			if !ok {
+				return unauthorized(c, `Bearer realm="`+authRealm+`"`)
+			}
+			if keys == nil {
+				return unauthorized(c, `Bearer realm="`+authRealm+`", error="invalid_token"`)
+			}
+			if _, err := verifier.Verify(token); err != nil {
+				log.Debug().Err(err).Msg("rejected API token")
+				return unauthorized(c, `Bearer realm="`+authRealm+`", error="invalid_token"`)
+			}
+			return c.Next()
+		}
+	default:
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+}
+
+func basicCredentials(c *fiber.Ctx) (string, string, bool) {
+	scheme, encoded, found := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+	if !found || !strings.EqualFold(scheme, "Basic") {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return "", "", false
+	}
+	return strings.Cut(string(decoded), ":")
+}
+
+func unauthorized(c *fiber.Ctx, challenge string) error {
+	c.Set(fiber.HeaderWWWAuthenticate, challenge)
+	return c.SendStatus(fiber.StatusUnauthorized)
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cosmoparrot/internal/auth"
+	"cosmoparrot/internal/config"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"embed"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func restoreConfig(t *testing.T) {
+	original := config.LoadedConfiguration
+	t.Cleanup(func() { config.LoadedConfiguration = original })
+}
+
+// listenLocal listens on a free local port.
+func listenLocal(t *testing.T) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	return ln
+}
+
+// serveApp serves the app on a local listener and returns its address, for
+// tests that need a real connection.
+func serveApp(t *testing.T, app *fiber.App) string {
+	t.Helper()
+	ln := listenLocal(t)
+	go func() { _ = app.Listener(ln) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+	return ln.Addr().String()
+}
+
+func statusOf(t *testing.T, app *fiber.App, r *http.Request) int {
+	t.Helper()
+	resp, err := app.Test(r, -1)
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func TestAuth_Bearer(t *testing.T) {
+	restoreConfig(t)
+	config.LoadedConfiguration.APIAuthMode = "bearer"
+	config.LoadedConfiguration.APIAuthToken = "admin-token"
+	app := NewApp(embed.FS{})
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/requests", nil)
+	resp, err := app.Test(r, -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, `Bearer realm="cosmoparrot"`, resp.Header.Get("WWW-Authenticate"))
+
+	r = httptest.NewRequest(http.MethodGet, "/api/v1/requests", nil)
+	r.Header.Set("Authorization", "Bearer wrong-token")
+	assert.Equal(t, http.StatusUnauthorized, statusOf(t, app, r))
+
+	r = httptest.NewRequest(http.MethodGet, "/api/v1/requests", nil)
+	r.Header.Set("Authorization", "Bearer admin-token")
+	assert.Equal(t, http.StatusOK, statusOf(t, app, r))
+
+	// the endpoints used by the systems under test stay open
+	assert.Equal(t, http.StatusOK, statusOf(t, app, httptest.NewRequest(http.MethodGet, "/api/v1/devnull", nil)))
+	assert.Equal(t, http.StatusOK, statusOf(t, app, httptest.NewRequest(http.MethodPut, "/echo", nil)))
+}
+
+func TestAuth_Basic(t *testing.T) {
+	restoreConfig(t)
+	config.LoadedConfiguration.APIAuthMode = "basic"
+	config.LoadedConfiguration.APIAuthUsername = "admin"
+	config.LoadedConfiguration.APIAuthPassword = "secret"
+	app := NewApp(embed.FS{})
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/requests", nil)
+	r.SetBasicAuth("admin", "wrong")
+	assert.Equal(t, http.StatusUnauthorized, statusOf(t, app, r))
+
+	r = httptest.NewRequest(http.MethodGet, "/api/v1/requests", nil)
+	r.SetBasicAuth("admin", "secret")
+	assert.Equal(t, http.StatusOK, statusOf(t, app, r))
+}
+
+// writeTestJWKS generates a signing key and writes its public key as JWKS
+// with the key id "admin".
+func writeTestJWKS(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jwk, err := auth.NewJWK(key.Public(), "admin", "ES256")
+	require.NoError(t, err)
+	data, err := json.Marshal(auth.KeySet{Keys: []auth.JWK{jwk}})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return key, path
+}
+
+func signTestJWT(t *testing.T, key *ecdsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = "admin"
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestAuth_JWT(t *testing.T) {
+	key, jwksFile := writeTestJWKS(t)
+
+	restoreConfig(t)
+	config.LoadedConfiguration.APIAuthMode = "jwt"
+	config.LoadedConfiguration.APIAuthJwksFile = jwksFile
+	config.LoadedConfiguration.APIAuthJwtAudience = "cosmoparrot"
+	app := NewApp(embed.FS{})
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/requests", nil)
+	r.Header.Set("Authorization", "Bearer "+signTestJWT(t, key, jwt.MapClaims{"aud": "other", "exp": time.Now().Add(time.Minute).Unix()}))
+	resp, err := app.Test(r, -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("WWW-Authenticate"), `error="invalid_token"`)
+
+	r = httptest.NewRequest(http.MethodGet, "/api/v1/requests", nil)
+	r.Header.Set("Authorization", "Bearer "+signTestJWT(t, key, jwt.MapClaims{"aud": "cosmoparrot", "exp": time.Now().Add(time.Minute).Unix()}))
+	assert.Equal(t, http.StatusOK, statusOf(t, app, r))
+}
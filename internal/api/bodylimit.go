@@ -0,0 +1,96 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cosmoparrot/internal/config"
+	"errors"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var errBodyTooLarge = errors.New("request body exceeds the body limit")
+
+// handleBodyLimit answers requests whose Content-Length exceeds bodyLimit with
+// 413. With StreamRequestBody, fasthttp streams such bodies to the handlers
+// instead of rejecting them. Bodies without a Content-Length are checked when
+// they are read, see bufferBody and drainBody.
+func handleBodyLimit(c *fiber.Ctx) error {
+	if c.Request().Header.ContentLength() > config.LoadedConfiguration.BodyLimit {
+		return rejectBody(c)
+	}
+	return c.Next()
+}
+
+// bufferBody reads a streamed body without a Content-Length up to bodyLimit
+// for handlers that need the whole body, and answers larger ones with 413.
+func bufferBody(c *fiber.Ctx) error {
+	if c.Request().Header.ContentLength() >= 0 || !c.Request().IsBodyStream() {
+		return c.Next()
+	}
+
+	limit := config.LoadedConfiguration.BodyLimit
+	body, err := io.ReadAll(io.LimitReader(c.Context().RequestBodyStream(), int64(limit)+1))
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+	if len(body) > limit {
+		return rejectBody(c)
+	}
+	c.Request().SetBody(body)
+	return c.Next()
+}
+
+// bufferEchoBody buffers the body of echo requests unless it is neither
+// mirrored nor signed. The echo handler drains other bodies itself.
+func bufferEchoBody(c *fiber.Ctx) error {
+	if !getMirrorBody(c) && !config.LoadedConfiguration.SignatureEnabled {
+		return c.Next()
+	}
+	return bufferBody(c)
+}
+
+// drainBody discards the streamed body without holding it in memory and
+// returns its size. Bodies without a Content-Length fail with errBodyTooLarge
+// once they exceed bodyLimit; the others were checked by handleBodyLimit.
+func drainBody(c *fiber.Ctx) (int64, error) {
+	stream := c.Context().RequestBodyStream()
+	if stream == nil {
+		return int64(len(c.Request().Body())), nil
+	}
+	if c.Request().Header.ContentLength() < 0 {
+		stream = &limitedReader{r: stream, remaining: int64(config.LoadedConfiguration.BodyLimit)}
+	}
+	return io.Copy(io.Discard, stream)
+}
+
+// rejectBody answers with 413. The rest of the body is not read, so the
+// connection is closed.
+func rejectBody(c *fiber.Ctx) error {
+	c.Context().SetConnectionClose()
+	return c.SendStatus(fiber.StatusRequestEntityTooLarge)
+}
+
+// limitedReader fails with errBodyTooLarge instead of returning more than
+// remaining bytes.
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	// one byte more than remaining tells whether the limit is exceeded
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	if int64(n) > l.remaining {
+		l.remaining = 0
+		return n - 1, errBodyTooLarge
+	}
+	l.remaining -= int64(n)
+	return n, err
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"crypto/tls"
+	"math/rand"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// The connection failures that can be requested with ?chaos on the echo path.
+const (
+	chaosClose    = "close"
+	chaosReset    = "reset"
+	chaosHang     = "hang"
+	chaosTruncate = "truncate"
+	chaosGarbage  = "garbage"
+)
+
+var chaosModes = []string{chaosClose, chaosReset, chaosHang, chaosTruncate, chaosGarbage}
+
+// chaosHangTimeout bounds how long a connection hangs after the headers.
+const chaosHangTimeout = time.Duration(maxResponseDelayMs) * time.Millisecond
+
+const chaosGarbageSize = 512
+
+// getChaos reads the optional "chaos" query parameter. Returns "" for missing or
+// unknown modes and for requests received via net/http, whose connections
+// cannot be hijacked.
+func getChaos(c *fiber.Ctx) string {
+	mode := strings.ToLower(strings.TrimSpace(queryCaseInsensitive(c, "chaos")))
+	if !slices.Contains(chaosModes, mode) || isBridged(c) {
+		return ""
+	}
+	return mode
+}
+
+// injectChaos answers the request by misbehaving on the connection instead of
+// sending the response, which is only used for the modes that send parts of it.
+func injectChaos(c *fiber.Ctx, mode string, code int, body []byte) error {
+	c.Status(code)
+	c.Response().Header.SetContentType(fiber.MIMEApplicationJSON)
+	c.Response().Header.SetContentLength(len(body))
+	// the request context is reset before the hijacked connection is handed over
+	header := slices.Clone(c.Response().Header.Header())
+	body = slices.Clone(body)
+	raw := c.Context().Conn()
+
+	c.Context().HijackSetNoResponse(true)
+	c.Context().Hijack(func(conn net.Conn) {
+		log.Debug().Str("mode", mode).Msg("injecting connection chaos")
+		switch mode {
+		case chaosReset:
+			// closing with a zero linger time sends RST instead of FIN
+			if tcp := underlyingTCPConn(raw); tcp != nil {
+				_ = tcp.SetLinger(0)
+			}
+		case chaosHang:
+			if _, err := conn.Write(header); err != nil {
+				return
+			}
+			// wait for the client to give up
+			_ = conn.SetReadDeadline(time.Now().Add(chaosHangTimeout))
+			_, _ = conn.Read(make([]byte, 1))
+		case chaosTruncate:
+			_, _ = conn.Write(append(header, body[:len(body)/2]...))
+		case chaosGarbage:
+			garbage := make([]byte, chaosGarbageSize)
+			_, _ = rand.Read(garbage)
+			_, _ = conn.Write(garbage)
+		}
+		// fasthttp closes the connection when the handler returns
+	})
+	return nil
+}
+
+// underlyingTCPConn unwraps TLS and additional listener connections.
+func underlyingTCPConn(conn net.Conn) *net.TCPConn {
+	for {
+		switch c := conn.(type) {
+		case *net.TCPConn:
+			return c
+		case *tls.Conn:
+			conn = c.NetConn()
+		case *configuredConn:
+			conn = c.Conn
+		default:
+			return nil
+		}
+	}
+}
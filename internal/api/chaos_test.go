@@ -0,0 +1,82 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bufio"
+	"cosmoparrot/internal/cache"
+	"cosmoparrot/internal/config"
+	"embed"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sendChaosRequest serves the app on a local listener, sends a request with
+// the chaos mode on a raw connection and returns the connection to read from.
+func sendChaosRequest(t *testing.T, mode string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	restoreConfig(t)
+	config.LoadedConfiguration.MethodResponseCodeMap = map[string]int{}
+	app := NewApp(embed.FS{})
+
+	conn, err := net.Dial("tcp", serveApp(t, app))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+	_, err = io.WriteString(conn, "POST /orders?chaos="+mode+" HTTP/1.1\r\nHost: cosmoparrot\r\nX-Request-Key: chaos-key\r\nContent-Length: 2\r\n\r\n{}")
+	require.NoError(t, err)
+	return conn, bufio.NewReader(conn)
+}
+
+func TestChaos_Close(t *testing.T) {
+	_, r := sendChaosRequest(t, "close")
+	_, err := r.ReadByte()
+	assert.ErrorIs(t, err, io.EOF)
+
+	// the request is still stored
+	_, found := cache.Current.Get("chaos-key")
+	assert.True(t, found)
+}
+
+func TestChaos_Reset(t *testing.T) {
+	_, r := sendChaosRequest(t, "reset")
+	_, err := r.ReadByte()
+	assert.ErrorIs(t, err, syscall.ECONNRESET)
+}
+
+func TestChaos_Hang(t *testing.T) {
+	conn, r := sendChaosRequest(t, "hang")
+	resp, err := http.ReadResponse(r, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Positive(t, resp.ContentLength)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(100*time.Millisecond)))
+	_, err = resp.Body.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+}
+
+func TestChaos_Truncate(t *testing.T) {
+	_, r := sendChaosRequest(t, "truncate")
+	resp, err := http.ReadResponse(r, nil)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, resp.ContentLength/2, int64(len(body)))
+}
+
+func TestChaos_Garbage(t *testing.T) {
+	_, r := sendChaosRequest(t, "garbage")
+	_, err := http.ReadResponse(r, nil)
+	assert.Error(t, err)
+}
@@ -6,38 +6,111 @@ package api
 
 import (
 	"cosmoparrot/internal/config"
-	"fmt"
+	"errors"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func getLoggerConfig() logger.Config {
-	return logger.Config{
-		Next: func(c *fiber.Ctx) bool {
-			// Skip logging entirely when request logging is disabled.
-			if !config.LoadedConfiguration.RequestLogging {
-				return true
-			}
-			return strings.HasPrefix(c.Path(), "/api/v1/devnull")
-		},
-		// The request body is intentionally not logged.
-		Format:   "${green}→ Request received:\n${reset}${time} | ${status} - ${method} ${path}\n${green}→ Request headers:${magenta}\n${custom_tag}${reset}\n",
-		TimeZone: "UTC",
-		CustomTags: map[string]logger.LogFunc{
-			"custom_tag": func(output logger.Buffer, c *fiber.Ctx, data *logger.Data, extraParam string) (int, error) {
-				var str string
-				for k, v := range c.GetReqHeaders() {
-					str += fmt.Sprintf("%v: %v\n", k, v)
-				}
-
-				return output.WriteString(str)
-			},
-		},
+// spanContextKey is the fiber local under which the tracing middleware leaves
+// the span context of a request, so the request log can be correlated.
+const spanContextKey = "cosmoparrot.spanContext"
+
+func skipRequestLog(c *fiber.Ctx) bool {
+	// Skip logging entirely when request logging is disabled.
+	if !config.LoadedConfiguration.RequestLogging {
+		return true
+	}
+	if strings.HasPrefix(c.Path(), "/api/v1/devnull") || c.Path() == metricsPath {
+		return true
 	}
+	return !pathLogged(c.Path())
 }
 
 func createNewLogHandler() fiber.Handler {
-	return logger.New(getLoggerConfig())
+	sampler := &requestLogSampler{}
+	return func(c *fiber.Ctx) error {
+		if skipRequestLog(c) {
+			return c.Next()
+		}
+
+		start := time.Now()
+		err := c.Next()
+		if status := responseStatus(c, err); sampler.sample(status) {
+			logRequest(c, status, time.Since(start))
+		}
+
+		return err
+	}
+}
+
+// logRequest writes one structured record per request. Headers are logged as an
+// object, with the values of the configured headers redacted. The request body
+// is only logged on request and capped at the configured size. Like an access
+// log, the records are written no matter the logLevel, but marked as info.
+func logRequest(c *fiber.Ctx, status int, latency time.Duration) {
+	event := log.WithLevel(zerolog.NoLevel)
+	if !event.Enabled() {
+		return
+	}
+	event.Str(zerolog.LevelFieldName, zerolog.LevelInfoValue)
+
+	cfg := config.LoadedConfiguration
+	headers := zerolog.Dict()
+	for name, values := range redactHeaders(c.GetReqHeaders()) {
+		if len(values) == 1 {
+			headers.Str(name, values[0])
+		} else {
+			headers.Strs(name, values)
+		}
+	}
+
+	event.
+		Int("status", status).
+		Str("method", c.Method()).
+		Str("path", c.Path()).
+		Float64("latencyMs", float64(latency.Microseconds())/1000).
+		Dict("headers", headers)
+
+	if traceID, spanID := requestTraceContext(c); traceID != "" {
+		event.Str("traceId", traceID).Str("spanId", spanID)
+	}
+
+	if cfg.RequestLogBody {
+		body := c.Request().Body()
+		if len(body) > cfg.RequestLogBodyMaxSize {
+			body = body[:cfg.RequestLogBodyMaxSize]
+			event.Bool("bodyTruncated", true)
+		}
+		event.Bytes("body", body)
+	}
+
+	event.Msg("request received")
+}
+
+// requestTraceContext returns the trace and span id of the request's server span
+// if the request was traced, or else the ones propagated by the caller.
+func requestTraceContext(c *fiber.Ctx) (string, string) {
+	if spanContext, ok := c.Locals(spanContextKey).(trace.SpanContext); ok && spanContext.IsValid() {
+		return spanContext.TraceID().String(), spanContext.SpanID().String()
+	}
+	return extractTraceContext(c)
+}
+
+// responseStatus returns the status code of the response. If a handler returned
+// an error, the error handler only runs after the middleware chain, so the
+// status it will apply is derived from the error.
+func responseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	return fiber.StatusInternalServerError
 }
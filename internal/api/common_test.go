@@ -7,48 +7,49 @@ package api
 import (
 	"bytes"
 	"cosmoparrot/internal/config"
-	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/logger"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
 )
 
-type byteSliceWriter struct {
-	b []byte
-}
-
-func (w *byteSliceWriter) Write(p []byte) (int, error) {
-	w.b = append(w.b, p...)
-	return len(p), nil
+// captureLogs redirects the global logger into a buffer for the duration of the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	original := log.Logger
+	log.Logger = zerolog.New(&buf)
+	t.Cleanup(func() { log.Logger = original })
+	return &buf
 }
 
-func TestLogFormat(t *testing.T) {
-	var w byteSliceWriter
-
-	c := getLoggerConfig()
-
-	// For this test we disable color log output
-	c.DisableColors = true
-
-	// Here we capture everything that is logged via our fiber middleware
-	c.Done = func(c *fiber.Ctx, logString []byte) {
-		w.Write(logString)
-	}
-
-	// Create a new Fiber app
+// newLoggedTestApp creates an app that logs requests to /test.
+func newLoggedTestApp() *fiber.App {
 	app := fiber.New()
-
-	// Use the custom logger as middleware
-	app.Use(logger.New(c))
-
-	// Define a test route
+	app.Use(createNewLogHandler())
 	app.Post("/test", func(c *fiber.Ctx) error {
 		// Simulate request body handling
 		return c.SendString("Hello, Fiber!")
 	})
+	return app
+}
+
+func decodeLogRecord(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	return record
+}
+
+func TestLogFormat(t *testing.T) {
+	buf := captureLogs(t)
+	app := newLoggedTestApp()
 
 	// Create a test request
 	req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBufferString(`{"key": "value"}`))
@@ -60,16 +61,38 @@ func TestLogFormat(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 
-	// Get the captured log output
-	logOutput := string(w.b)
+	record := decodeLogRecord(t, buf)
 
 	// Validate log contains expected data
-	assert.Contains(t, logOutput, "→ Request received:")
-	assert.Contains(t, logOutput, "POST /test")
-	assert.Contains(t, logOutput, "200")
-	// The request body must not be logged.
-	assert.NotContains(t, logOutput, `"key": "value"`)
-	assert.Contains(t, logOutput, "X-Custom-Header: [TestValue]")
+	assert.Equal(t, "request received", record["message"])
+	assert.Equal(t, "info", record["level"])
+	assert.Equal(t, "POST", record["method"])
+	assert.Equal(t, "/test", record["path"])
+	assert.Equal(t, float64(200), record["status"])
+	assert.Contains(t, record, "latencyMs")
+	// The request body is not logged by default.
+	assert.NotContains(t, record, "body")
+	assert.NotContains(t, buf.String(), `"key": "value"`)
+
+	headers := record["headers"].(map[string]any)
+	assert.Equal(t, "TestValue", headers["X-Custom-Header"])
+	assert.Equal(t, "application/json", headers["Content-Type"])
+}
+
+func TestLogIgnoresLogLevel(t *testing.T) {
+	buf := captureLogs(t)
+	original := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+	t.Cleanup(func() { zerolog.SetGlobalLevel(original) })
+	app := newLoggedTestApp()
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/test", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	record := decodeLogRecord(t, buf)
+	assert.Equal(t, "request received", record["message"])
+	assert.Equal(t, "info", record["level"])
 }
 
 func TestRequestLoggingDisabled(t *testing.T) {
@@ -78,19 +101,8 @@ func TestRequestLoggingDisabled(t *testing.T) {
 	config.LoadedConfiguration.RequestLogging = false
 	defer func() { config.LoadedConfiguration.RequestLogging = original }()
 
-	var w byteSliceWriter
-
-	c := getLoggerConfig()
-	c.DisableColors = true
-	c.Done = func(c *fiber.Ctx, logString []byte) {
-		w.Write(logString)
-	}
-
-	app := fiber.New()
-	app.Use(logger.New(c))
-	app.Post("/test", func(c *fiber.Ctx) error {
-		return c.SendString("Hello, Fiber!")
-	})
+	buf := captureLogs(t)
+	app := newLoggedTestApp()
 
 	req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBufferString(`{"key": "value"}`))
 	req.Header.Set("Content-Type", "application/json")
@@ -100,5 +112,61 @@ func TestRequestLoggingDisabled(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 
 	// Nothing should have been logged when request logging is disabled
-	assert.Empty(t, w.b)
+	assert.Empty(t, buf.Bytes())
+}
+
+func TestLogBody(t *testing.T) {
+	original := config.LoadedConfiguration
+	config.LoadedConfiguration.RequestLogBody = true
+	config.LoadedConfiguration.RequestLogBodyMaxSize = 10
+	defer func() { config.LoadedConfiguration = original }()
+
+	buf := captureLogs(t)
+	app := newLoggedTestApp()
+
+	req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBufferString(strings.Repeat("x", 20)))
+	_, err := app.Test(req, -1)
+	assert.NoError(t, err)
+
+	record := decodeLogRecord(t, buf)
+	assert.Equal(t, strings.Repeat("x", 10), record["body"])
+	assert.Equal(t, true, record["bodyTruncated"])
+}
+
+func TestLogRedactsHeaders(t *testing.T) {
+	original := config.LoadedConfiguration.RedactedHeaders
+	config.LoadedConfiguration.RedactedHeaders = []string{"authorization", "X-Secret"}
+	defer func() { config.LoadedConfiguration.RedactedHeaders = original }()
+
+	buf := captureLogs(t)
+	app := newLoggedTestApp()
+
+	req := httptest.NewRequest(http.MethodPost, "/test", nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set("X-Secret", "secret-value")
+	req.Header.Set("X-Visible", "visible-value")
+	_, err := app.Test(req, -1)
+	assert.NoError(t, err)
+
+	assert.NotContains(t, buf.String(), "secret-token")
+	assert.NotContains(t, buf.String(), "secret-value")
+
+	headers := decodeLogRecord(t, buf)["headers"].(map[string]any)
+	assert.Equal(t, redactedValue, headers["Authorization"])
+	assert.Equal(t, redactedValue, headers["X-Secret"])
+	assert.Equal(t, "visible-value", headers["X-Visible"])
+}
+
+func TestLogTraceCorrelation(t *testing.T) {
+	buf := captureLogs(t)
+	app := newLoggedTestApp()
+
+	req := httptest.NewRequest(http.MethodPost, "/test", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, err := app.Test(req, -1)
+	assert.NoError(t, err)
+
+	record := decodeLogRecord(t, buf)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["traceId"])
+	assert.Equal(t, "00f067aa0ba902b7", record["spanId"])
 }
@@ -0,0 +1,230 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cosmoparrot/internal/cache"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// consumerPathPrefix is where the echo handler impersonates consumer profiles,
+// e.g. /c/billing/events.
+const consumerPathPrefix = "/c/"
+
+// consumerKey is the fiber local that carries the consumerProfile of a request.
+const consumerKey = "cosmoparrot.consumer"
+
+const defaultConsumerFaultResponseCode = fiber.StatusServiceUnavailable
+
+var consumerNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// consumerProfile holds the settings of a simulated consumer. They replace the
+// global settings for requests below its path prefix; query parameters still
+// take precedence.
+type consumerProfile struct {
+	Name                string         `json:"name"`
+	ResponseCode        int            `json:"responseCode,omitempty"`
+	MethodResponseCodes map[string]int `json:"methodResponseCodes,omitempty"`
+	// the delay is picked at random between MinDelayMs and MaxDelayMs
+	MinDelayMs int `json:"minDelayMs,omitempty"`
+	MaxDelayMs int `json:"maxDelayMs,omitempty"`
+	// StoreKey is used for requests without a store key header and defaults
+	// to the name
+	StoreKey string `json:"storeKey,omitempty"`
+	// FaultRate is the share of requests answered with FaultResponseCode
+	FaultRate         float64 `json:"faultRate,omitempty"`
+	FaultResponseCode int     `json:"faultResponseCode,omitempty"`
+}
+
+// validate checks the profile and fills in the defaults.
+func (p *consumerProfile) validate() error {
+	var errs []error
+	if !consumerNamePattern.MatchString(p.Name) {
+		errs = append(errs, fmt.Errorf("name %q must consist of 1 to 64 letters, digits, dots, dashes or underscores", p.Name))
+	}
+	if p.ResponseCode != 0 && (p.ResponseCode < 100 || p.ResponseCode > 599) {
+		errs = append(errs, fmt.Errorf("responseCode %d is not a valid HTTP status code", p.ResponseCode))
+	}
+	methodResponseCodes := make(map[string]int, len(p.MethodResponseCodes))
+	for method, code := range p.MethodResponseCodes {
+		if code < 100 || code > 599 {
+			errs = append(errs, fmt.Errorf("methodResponseCodes %s: %d is not a valid HTTP status code", method, code))
+		}
+		methodResponseCodes[strings.ToUpper(method)] = code
+	}
+	p.MethodResponseCodes = methodResponseCodes
+	if p.MaxDelayMs == 0 {
+		p.MaxDelayMs = p.MinDelayMs
+	}
+	if p.MinDelayMs < 0 || p.MaxDelayMs > maxResponseDelayMs || p.MinDelayMs > p.MaxDelayMs {
+		errs = append(errs, fmt.Errorf("minDelayMs and maxDelayMs must be ordered and between 0 and %d", maxResponseDelayMs))
+	}
+	if p.StoreKey == "" {
+		p.StoreKey = p.Name
+	}
+	if p.FaultRate < 0 || p.FaultRate > 1 {
+		errs = append(errs, fmt.Errorf("faultRate must be between 0 and 1"))
+	}
+	if p.FaultRate > 0 && p.FaultResponseCode == 0 {
+		p.FaultResponseCode = defaultConsumerFaultResponseCode
+	}
+	if p.FaultResponseCode != 0 && (p.FaultResponseCode < 100 || p.FaultResponseCode > 599) {
+		errs = append(errs, fmt.Errorf("faultResponseCode %d is not a valid HTTP status code", p.FaultResponseCode))
+	}
+	return errors.Join(errs...)
+}
+
+// delay returns a random delay of the delay profile.
+func (p *consumerProfile) delay() time.Duration {
+	ms := p.MinDelayMs
+	if p.MaxDelayMs > p.MinDelayMs {
+		ms += rand.Intn(p.MaxDelayMs - p.MinDelayMs + 1)
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// fault reports whether a request is to be answered with the fault response code.
+func (p *consumerProfile) fault() bool {
+	return p.FaultRate > 0 && rand.Float64() < p.FaultRate
+}
+
+// consumerRegistry holds the consumer profiles created via the admin API.
+type consumerRegistry struct {
+	mu       sync.RWMutex
+	profiles map[string]*consumerProfile
+}
+
+var consumers = &consumerRegistry{profiles: make(map[string]*consumerProfile)}
+
+func (r *consumerRegistry) get(name string) *consumerProfile {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.profiles[name]
+}
+
+// put creates or replaces a profile and reports whether it was created.
+func (r *consumerRegistry) put(profile *consumerProfile) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, found := r.profiles[profile.Name]
+	r.profiles[profile.Name] = profile
+	return !found
+}
+
+func (r *consumerRegistry) delete(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, found := r.profiles[name]
+	delete(r.profiles, name)
+	return found
+}
+
+func (r *consumerRegistry) list() []*consumerProfile {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]*consumerProfile, 0, len(r.profiles))
+	for _, profile := range r.profiles {
+		list = append(list, profile)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// handleConsumer resolves the consumer profile of requests below the consumer
+// path prefix for the echo handler. Unknown consumers are answered with 404.
+func handleConsumer(c *fiber.Ctx) error {
+	rest, found := strings.CutPrefix(c.Path(), consumerPathPrefix)
+	if !found {
+		return c.Next()
+	}
+	name, _, _ := strings.Cut(rest, "/")
+	profile := consumers.get(name)
+	if profile == nil {
+		return c.Status(fiber.StatusNotFound).SendString(fmt.Sprintf("consumer %q does not exist", name))
+	}
+	c.Locals(consumerKey, profile)
+	return c.Next()
+}
+
+// requestConsumer returns the consumer profile a request is addressed to, if any.
+func requestConsumer(c *fiber.Ctx) *consumerProfile {
+	profile, _ := c.Locals(consumerKey).(*consumerProfile)
+	return profile
+}
+
+func handleGetConsumers(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(consumers.list())
+}
+
+func handleGetConsumer(c *fiber.Ctx) error {
+	profile := consumers.get(c.Params("name"))
+	if profile == nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	return c.Status(fiber.StatusOK).JSON(profile)
+}
+
+// handlePutConsumer creates or replaces the consumer profile named in the path.
+func handlePutConsumer(c *fiber.Ctx) error {
+	profile := new(consumerProfile)
+	if err := json.Unmarshal(c.Body(), profile); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(fmt.Sprintf("invalid consumer profile: %v", err))
+	}
+	profile.Name = strings.Clone(c.Params("name"))
+	if err := profile.validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	status := fiber.StatusOK
+	if consumers.put(profile) {
+		status = fiber.StatusCreated
+	}
+	log.Info().Str("consumer", profile.Name).Msg("consumer profile saved")
+	return c.Status(status).JSON(profile)
+}
+
+func handleDeleteConsumer(c *fiber.Ctx) error {
+	if !consumers.delete(c.Params("name")) {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// handleGetConsumerRequests returns the stored requests of a consumer, no
+// matter which store key they were stored under.
+func handleGetConsumerRequests(c *fiber.Ctx) error {
+	name := c.Params("name")
+	list := []*request{}
+	for _, v := range cache.Current.Items() {
+		var requests []*request
+		if err := json.Unmarshal([]byte(v.Object.(string)), &requests); err != nil {
+			log.Error().Err(err).Msg("failed to deserialize data")
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		for _, r := range requests {
+			if r.Consumer == name {
+				list = append(list, r)
+			}
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Time.After(list[j].Time)
+	})
+	return c.Status(fiber.StatusOK).JSON(list)
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bytes"
+	"cosmoparrot/internal/cache"
+	"cosmoparrot/internal/config"
+	"embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func putConsumer(t *testing.T, app *fiber.App, name, profile string) *http.Response {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPut, "/api/v1/consumers/"+name, bytes.NewBufferString(profile))
+	resp, err := app.Test(r, -1)
+	require.NoError(t, err)
+	t.Cleanup(func() { consumers.delete(name) })
+	return resp
+}
+
+func TestConsumers_Admin(t *testing.T) {
+	app := NewApp(embed.FS{})
+
+	resp := putConsumer(t, app, "billing", `{"responseCode":202,"methodResponseCodes":{"delete":405},"minDelayMs":10}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	var profile consumerProfile
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&profile))
+	assert.Equal(t, consumerProfile{
+		Name:                "billing",
+		ResponseCode:        202,
+		MethodResponseCodes: map[string]int{"DELETE": 405},
+		MinDelayMs:          10,
+		MaxDelayMs:          10,
+		StoreKey:            "billing",
+	}, profile)
+
+	resp = putConsumer(t, app, "billing", `{"responseCode":204}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.StatusBadRequest, putConsumer(t, app, "billing", `{"faultRate":2}`).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, putConsumer(t, app, "bad%20name", `{}`).StatusCode)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/consumers", nil), -1)
+	require.NoError(t, err)
+	var profiles []consumerProfile
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&profiles))
+	require.Len(t, profiles, 1)
+	assert.Equal(t, 204, profiles[0].ResponseCode)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodDelete, "/api/v1/consumers/billing", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/consumers/billing", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestConsumers_Echo(t *testing.T) {
+	restoreConfig(t)
+	config.LoadedConfiguration.MethodResponseCodeMap = map[string]int{http.MethodGet: 500}
+	app := NewApp(embed.FS{})
+	cache.Current.Delete("orders")
+	cache.Current.Delete("orders-test")
+	putConsumer(t, app, "orders", `{"responseCode":202,"methodResponseCodes":{"PUT":409},"minDelayMs":20,"maxDelayMs":30}`)
+	putConsumer(t, app, "broken", `{"faultRate":1}`)
+
+	start := time.Now()
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/c/orders/events", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Equal(t, "orders", echoed(t, resp).Consumer)
+
+	r := httptest.NewRequest(http.MethodPut, "/c/orders/events?responseDelay=0", nil)
+	r.Header.Set("X-Request-Key", "orders-test")
+	resp, err = app.Test(r, -1)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/c/broken/events", nil), -1)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/c/broken/events?responseCode=201", nil), -1)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/c/unknown/events", nil), -1)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// captures are grouped by consumer, whatever their store key
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/consumers/orders/requests", nil), -1)
+	require.NoError(t, err)
+	var requests []*request
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&requests))
+	require.Len(t, requests, 2)
+	assert.Equal(t, http.MethodPut, requests[0].Method)
+	assert.Equal(t, "/c/orders/events", requests[1].Path)
+	_, found := cache.Current.Get("orders-test")
+	assert.True(t, found)
+}
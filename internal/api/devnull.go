@@ -5,11 +5,21 @@
 package api
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 )
 
 func handleDevNull(c *fiber.Ctx) error {
 	// With StreamRequestBody enabled, the body is never read into memory
 	// because we never call c.Body(). No deserialization, no caching, no logging.
+	// The body is drained to count it and to enforce the body limit on bodies
+	// without a Content-Length.
+	devNullRequestsTotal.Inc()
+	n, err := drainBody(c)
+	devNullBytesTotal.Add(float64(n))
+	if errors.Is(err, errBodyTooLarge) {
+		return rejectBody(c)
+	}
 	return c.SendStatus(getResponseCode(c))
 }
@@ -0,0 +1,351 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"cosmoparrot/internal/config"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// The echo service takes and returns arbitrary JSON objects, so it gets by
+// without generated code. Its descriptor is built by hand and registered for
+// server reflection.
+const (
+	grpcEchoFile    = "cosmoparrot/echo/v1/echo.proto"
+	grpcEchoService = "cosmoparrot.echo.v1.EchoService"
+)
+
+// The metadata that controls a call, mirroring the query parameters of the
+// echo handler.
+const (
+	grpcResponseCodeKey  = "x-response-code"
+	grpcResponseDelayKey = "x-response-delay"
+	grpcResponseCountKey = "x-response-count"
+)
+
+const maxGRPCResponseCount = 1000
+
+func init() {
+	method := func(name string, clientStreaming, serverStreaming bool) *descriptorpb.MethodDescriptorProto {
+		return &descriptorpb.MethodDescriptorProto{
+			Name:            proto.String(name),
+			InputType:       proto.String(".google.protobuf.Struct"),
+			OutputType:      proto.String(".google.protobuf.Struct"),
+			ClientStreaming: proto.Bool(clientStreaming),
+			ServerStreaming: proto.Bool(serverStreaming),
+		}
+	}
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String(grpcEchoFile),
+		Package:    proto.String("cosmoparrot.echo.v1"),
+		Dependency: []string{"google/protobuf/struct.proto"},
+		Syntax:     proto.String("proto3"),
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("EchoService"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				method("Echo", false, false),
+				method("ServerStream", false, true),
+				method("ClientStream", true, false),
+				method("BidiStream", true, true),
+			},
+		}},
+	}, protoregistry.GlobalFiles)
+	if err == nil {
+		err = protoregistry.GlobalFiles.RegisterFile(file)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("failed to register the gRPC echo service: %v", err))
+	}
+}
+
+// grpcEcho implements the echo service.
+type grpcEcho struct{}
+
+var grpcEchoServiceDesc = grpc.ServiceDesc{
+	ServiceName: grpcEchoService,
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Echo",
+		Handler: func(srv any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+			in := new(structpb.Struct)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			return srv.(*grpcEcho).echo(ctx, in)
+		},
+	}},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "ServerStream", ServerStreams: true, Handler: func(srv any, stream grpc.ServerStream) error {
+			return srv.(*grpcEcho).serverStream(stream)
+		}},
+		{StreamName: "ClientStream", ClientStreams: true, Handler: func(srv any, stream grpc.ServerStream) error {
+			return srv.(*grpcEcho).clientStream(stream)
+		}},
+		{StreamName: "BidiStream", ClientStreams: true, ServerStreams: true, Handler: func(srv any, stream grpc.ServerStream) error {
+			return srv.(*grpcEcho).bidiStream(stream)
+		}},
+	},
+	Metadata: grpcEchoFile,
+}
+
+// newGRPCServer builds the gRPC server with the echo service and reflection.
+func newGRPCServer() *grpc.Server {
+	server := grpc.NewServer(grpc.MaxRecvMsgSize(config.LoadedConfiguration.BodyLimit))
+	server.RegisterService(&grpcEchoServiceDesc, &grpcEcho{})
+	reflection.Register(server)
+	return server
+}
+
+// listenGRPC serves the gRPC echo service in the background.
+func listenGRPC(port int) {
+	addr := fmt.Sprintf(":%d", port)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatal().Err(err).Str("addr", addr).Msg("failed to listen for gRPC")
+	}
+	log.Info().Str("addr", addr).Msg("listening for gRPC")
+
+	server := newGRPCServer()
+	go func() {
+		if err := server.Serve(ln); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			log.Fatal().Err(err).Str("addr", addr).Msg("failed to listen for gRPC")
+		}
+	}()
+}
+
+// grpcCall holds what is known about a call from its metadata.
+type grpcCall struct {
+	key   string
+	code  codes.Code
+	delay time.Duration
+	count int
+	// echoed is the metadata that is sent back as response headers
+	echoed metadata.MD
+	// template is the request that messages are recorded with
+	template request
+}
+
+func newGRPCCall(ctx context.Context) *grpcCall {
+	md, _ := metadata.FromIncomingContext(ctx)
+	method, _ := grpc.Method(ctx)
+	headers := make(http.Header, len(md))
+	echoed := metadata.MD{}
+	for key, values := range md {
+		headers[http.CanonicalHeaderKey(key)] = values
+		if strings.HasPrefix(key, ":") || strings.HasPrefix(key, "grpc-") || key == "content-type" || key == "user-agent" || key == "te" {
+			continue
+		}
+		// sensitive metadata is not sent back
+		if !isRedactedHeader(key) {
+			echoed[key] = values
+		}
+	}
+
+	call := &grpcCall{
+		code:   grpcResponseCode(md),
+		delay:  time.Duration(metadataInt(md, grpcResponseDelayKey, 0, 0, maxResponseDelayMs)) * time.Millisecond,
+		count:  metadataInt(md, grpcResponseCountKey, 1, 0, maxGRPCResponseCount),
+		echoed: echoed,
+	}
+	for _, name := range config.LoadedConfiguration.StoreKeyRequestHeaders {
+		if values := md.Get(name); len(values) > 0 {
+			call.key = values[0]
+			break
+		}
+	}
+
+	call.template = *newRecord(method, http.MethodPost, "HTTP/2.0", headers)
+	return call
+}
+
+// record stores a received message with the call under the store key.
+func (c *grpcCall) record(message *structpb.Struct) {
+	if c.key == "" {
+		return
+	}
+	body, err := protojson.Marshal(message)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to serialize gRPC message")
+		return
+	}
+	reqData := c.template
+	reqData.Time = time.Now()
+	reqData.Body = json.RawMessage(body)
+	if _, err := storeRequest(c.key, &reqData); err != nil {
+		log.Error().Err(err).Str("key", c.key).Msg("failed to record gRPC message")
+	}
+}
+
+// wait applies the requested delay unless the call is cancelled first.
+func (c *grpcCall) wait(ctx context.Context) error {
+	if c.delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(c.delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// status returns the requested status, nil for OK.
+func (c *grpcCall) status() error {
+	if c.code == codes.OK {
+		return nil
+	}
+	return status.Errorf(c.code, "requested status %s", c.code)
+}
+
+func (e *grpcEcho) echo(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+	call := newGRPCCall(ctx)
+	if err := grpc.SetHeader(ctx, call.echoed); err != nil {
+		return nil, err
+	}
+	call.record(in)
+	if err := call.wait(ctx); err != nil {
+		return nil, err
+	}
+	if err := call.status(); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+// serverStream echoes the request as often as requested before it ends the
+// call with the requested status.
+func (e *grpcEcho) serverStream(stream grpc.ServerStream) error {
+	call := newGRPCCall(stream.Context())
+	in := new(structpb.Struct)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	call.record(in)
+	if err := stream.SendHeader(call.echoed); err != nil {
+		return err
+	}
+	for range call.count {
+		if err := call.wait(stream.Context()); err != nil {
+			return err
+		}
+		if err := stream.SendMsg(in); err != nil {
+			return err
+		}
+	}
+	return call.status()
+}
+
+// clientStream answers with all received messages once the client is done.
+func (e *grpcEcho) clientStream(stream grpc.ServerStream) error {
+	call := newGRPCCall(stream.Context())
+	if err := stream.SendHeader(call.echoed); err != nil {
+		return err
+	}
+	var messages []any
+	for {
+		in := new(structpb.Struct)
+		if err := stream.RecvMsg(in); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+		call.record(in)
+		messages = append(messages, in.AsMap())
+	}
+	if err := call.wait(stream.Context()); err != nil {
+		return err
+	}
+	if err := call.status(); err != nil {
+		return err
+	}
+	out, err := structpb.NewStruct(map[string]any{"messages": messages})
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return stream.SendMsg(out)
+}
+
+// bidiStream echoes every message and ends the call with the requested status
+// once the client is done.
+func (e *grpcEcho) bidiStream(stream grpc.ServerStream) error {
+	call := newGRPCCall(stream.Context())
+	if err := stream.SendHeader(call.echoed); err != nil {
+		return err
+	}
+	for {
+		in := new(structpb.Struct)
+		if err := stream.RecvMsg(in); errors.Is(err, io.EOF) {
+			return call.status()
+		} else if err != nil {
+			return err
+		}
+		call.record(in)
+		if err := call.wait(stream.Context()); err != nil {
+			return err
+		}
+		if err := stream.SendMsg(in); err != nil {
+			return err
+		}
+	}
+}
+
+// grpcResponseCode reads the requested status, given by number or name such as
+// NOT_FOUND. Returns OK for missing or unknown values.
+func grpcResponseCode(md metadata.MD) codes.Code {
+	values := md.Get(grpcResponseCodeKey)
+	if len(values) == 0 {
+		return codes.OK
+	}
+	raw := strings.TrimSpace(values[0])
+	if n, err := strconv.Atoi(raw); err == nil {
+		if n < 0 || n > int(codes.Unauthenticated) {
+			return codes.OK
+		}
+		return codes.Code(n)
+	}
+	var code codes.Code
+	if err := code.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(raw)))); err != nil {
+		return codes.OK
+	}
+	return code
+}
+
+// metadataInt reads an integer metadata value. Returns fallback for missing,
+// non-integer or out-of-range values.
+func metadataInt(md metadata.MD, key string, fallback, minimum, maximum int) int {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return fallback
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(values[0]))
+	if err != nil || n < minimum || n > maximum {
+		return fallback
+	}
+	return n
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"cosmoparrot/internal/cache"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// dialGRPC serves the gRPC echo service on a local listener and connects to it.
+func dialGRPC(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+	ln := listenLocal(t)
+	server := newGRPCServer()
+	go func() { _ = server.Serve(ln) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func grpcContext(t *testing.T, pairs ...string) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return metadata.NewOutgoingContext(ctx, metadata.Pairs(pairs...))
+}
+
+func TestGRPC_Echo(t *testing.T) {
+	conn := dialGRPC(t)
+	cache.Current.Delete("grpc-key")
+	ctx := grpcContext(t, "x-request-key", "grpc-key", "x-custom", "value")
+
+	in, err := structpb.NewStruct(map[string]any{"hello": "world"})
+	require.NoError(t, err)
+	out := new(structpb.Struct)
+	var header metadata.MD
+	require.NoError(t, conn.Invoke(ctx, "/"+grpcEchoService+"/Echo", in, out, grpc.Header(&header)))
+	assert.Equal(t, "world", out.AsMap()["hello"])
+	assert.Equal(t, []string{"value"}, header.Get("x-custom"))
+
+	stored, found := cache.Current.Get("grpc-key")
+	require.True(t, found)
+	var requests []*request
+	require.NoError(t, json.Unmarshal([]byte(stored.(string)), &requests))
+	require.Len(t, requests, 1)
+	assert.Equal(t, "/cosmoparrot.echo.v1.EchoService/Echo", requests[0].Path)
+	assert.Equal(t, []string{"value"}, requests[0].Headers["X-Custom"])
+	assert.JSONEq(t, `{"hello":"world"}`, string(requests[0].Body))
+}
+
+func TestGRPC_RedactedMetadata(t *testing.T) {
+	conn := dialGRPC(t)
+	ctx := grpcContext(t, "authorization", "Bearer secret", "x-custom", "value")
+
+	in, err := structpb.NewStruct(map[string]any{})
+	require.NoError(t, err)
+	var header metadata.MD
+	require.NoError(t, conn.Invoke(ctx, "/"+grpcEchoService+"/Echo", in, new(structpb.Struct), grpc.Header(&header)))
+	assert.Equal(t, []string{"value"}, header.Get("x-custom"))
+	assert.Empty(t, header.Get("authorization"))
+}
+
+func TestGRPC_StatusAndDelay(t *testing.T) {
+	conn := dialGRPC(t)
+	in := &structpb.Struct{}
+
+	start := time.Now()
+	ctx := grpcContext(t, "x-response-code", "NOT_FOUND", "x-response-delay", "50")
+	err := conn.Invoke(ctx, "/"+grpcEchoService+"/Echo", in, new(structpb.Struct))
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	err = conn.Invoke(grpcContext(t, "x-response-code", "14"), "/"+grpcEchoService+"/Echo", in, new(structpb.Struct))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestGRPC_Streams(t *testing.T) {
+	conn := dialGRPC(t)
+	in, err := structpb.NewStruct(map[string]any{"n": 1})
+	require.NoError(t, err)
+
+	// the server stream fails after echoing the request twice
+	desc := &grpc.StreamDesc{ServerStreams: true}
+	ctx := grpcContext(t, "x-response-count", "2", "x-response-code", "ABORTED")
+	stream, err := conn.NewStream(ctx, desc, "/"+grpcEchoService+"/ServerStream")
+	require.NoError(t, err)
+	require.NoError(t, stream.SendMsg(in))
+	require.NoError(t, stream.CloseSend())
+	for range 2 {
+		require.NoError(t, stream.RecvMsg(new(structpb.Struct)))
+	}
+	assert.Equal(t, codes.Aborted, status.Code(stream.RecvMsg(new(structpb.Struct))))
+
+	desc = &grpc.StreamDesc{ClientStreams: true}
+	stream, err = conn.NewStream(grpcContext(t), desc, "/"+grpcEchoService+"/ClientStream")
+	require.NoError(t, err)
+	require.NoError(t, stream.SendMsg(in))
+	require.NoError(t, stream.SendMsg(in))
+	require.NoError(t, stream.CloseSend())
+	out := new(structpb.Struct)
+	require.NoError(t, stream.RecvMsg(out))
+	assert.Len(t, out.AsMap()["messages"], 2)
+
+	desc = &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}
+	stream, err = conn.NewStream(grpcContext(t), desc, "/"+grpcEchoService+"/BidiStream")
+	require.NoError(t, err)
+	require.NoError(t, stream.SendMsg(in))
+	out = new(structpb.Struct)
+	require.NoError(t, stream.RecvMsg(out))
+	assert.Equal(t, float64(1), out.AsMap()["n"])
+	require.NoError(t, stream.CloseSend())
+	assert.ErrorIs(t, stream.RecvMsg(out), io.EOF)
+}
+
+func TestGRPC_Reflection(t *testing.T) {
+	client := reflectionpb.NewServerReflectionClient(dialGRPC(t))
+	stream, err := client.ServerReflectionInfo(grpcContext(t))
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	var services []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	assert.Contains(t, services, grpcEchoService)
+
+	require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: grpcEchoService},
+	}))
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.GetFileDescriptorResponse().GetFileDescriptorProto())
+}
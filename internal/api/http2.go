@@ -0,0 +1,135 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cosmoparrot/internal/config"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/valyala/fasthttp"
+)
+
+// The net/http listener leaves the negotiated protocol and the TLS state of
+// bridged requests in these fiber locals, since the fasthttp request does not
+// carry them.
+const (
+	protocolKey = "cosmoparrot.protocol"
+	tlsStateKey = "cosmoparrot.tlsState"
+)
+
+// newHTTP2Server serves the app via net/http, which unlike fasthttp speaks
+// HTTP/2. Without TLS it accepts HTTP/2 with prior knowledge (h2c), with TLS
+// HTTP/2 is negotiated via ALPN. HTTP/1.1 is accepted either way.
+func newHTTP2Server(app *fiber.App, tlsConfig *tls.Config) *http.Server {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	if tlsConfig != nil {
+		protocols.SetHTTP2(true)
+		tlsConfig = tlsConfig.Clone()
+	} else {
+		protocols.SetUnencryptedHTTP2(true)
+	}
+	return &http.Server{
+		Handler:   newFiberBridge(app),
+		Protocols: protocols,
+		TLSConfig: tlsConfig,
+	}
+}
+
+// listenHTTP2 serves the app via net/http in the background, with TLS if a
+// TLS configuration is given.
+func listenHTTP2(app *fiber.App, port int, tlsConfig *tls.Config) {
+	addr := fmt.Sprintf(":%d", port)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatal().Err(err).Str("addr", addr).Msg("failed to listen for HTTP/2")
+	}
+	log.Info().Str("addr", addr).Bool("tls", tlsConfig != nil).Msg("listening for HTTP/2")
+
+	server := newHTTP2Server(app, tlsConfig)
+	go func() {
+		if tlsConfig != nil {
+			err = server.ServeTLS(ln, "", "")
+		} else {
+			err = server.Serve(ln)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal().Err(err).Str("addr", addr).Msg("failed to listen for HTTP/2")
+		}
+	}()
+}
+
+// newFiberBridge converts net/http requests to fasthttp and hands them to the
+// app. Unlike fiber's adaptor it keeps repeated headers as well as the
+// protocol and TLS state. Streamed responses are buffered.
+func newFiberBridge(app *fiber.App) http.HandlerFunc {
+	handler := app.Handler()
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := fasthttp.AcquireRequest()
+		defer fasthttp.ReleaseRequest(req)
+
+		if r.Body != nil {
+			body := http.MaxBytesReader(w, r.Body, int64(config.LoadedConfiguration.BodyLimit))
+			n, err := io.Copy(req.BodyWriter(), body)
+			if err != nil {
+				var maxBytesError *http.MaxBytesError
+				if errors.As(err, &maxBytesError) {
+					http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				} else {
+					http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				}
+				return
+			}
+			req.Header.SetContentLength(int(n))
+		}
+		req.Header.SetMethod(r.Method)
+		req.SetRequestURI(r.RequestURI)
+		req.Header.SetHost(r.Host)
+		for name, values := range r.Header {
+			for _, value := range values {
+				req.Header.Add(name, value)
+			}
+		}
+
+		remoteAddr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr)
+		if err != nil {
+			remoteAddr = &net.TCPAddr{}
+		}
+		var ctx fasthttp.RequestCtx
+		ctx.Init(req, remoteAddr, nil)
+		ctx.SetUserValue(protocolKey, r.Proto)
+		if r.TLS != nil {
+			ctx.SetUserValue(tlsStateKey, r.TLS)
+		}
+		handler(&ctx)
+
+		ctx.Response.Header.VisitAll(func(name, value []byte) {
+			w.Header().Add(string(name), string(value))
+		})
+		w.WriteHeader(ctx.Response.StatusCode())
+		_, _ = w.Write(ctx.Response.Body())
+	}
+}
+
+// isBridged reports whether a request was received via net/http. Its response
+// is buffered and its connection cannot be hijacked.
+func isBridged(c *fiber.Ctx) bool {
+	return c.Locals(protocolKey) != nil
+}
+
+// requestProtocol returns the protocol a request was received with, e.g. "HTTP/2.0".
+func requestProtocol(c *fiber.Ctx) string {
+	if protocol, ok := c.Locals(protocolKey).(string); ok {
+		return protocol
+	}
+	return string(c.Request().Header.Protocol())
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bytes"
+	"cosmoparrot/internal/config"
+	"crypto/tls"
+	"crypto/x509"
+	"embed"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveHTTP2 serves the app on a local net/http listener and returns its address.
+func serveHTTP2(t *testing.T, server *http.Server, withTLS bool) string {
+	t.Helper()
+	ln := listenLocal(t)
+	go func() {
+		if withTLS {
+			_ = server.ServeTLS(ln, "", "")
+		} else {
+			_ = server.Serve(ln)
+		}
+	}()
+	t.Cleanup(func() { _ = server.Close() })
+	return ln.Addr().String()
+}
+
+func echoed(t *testing.T, resp *http.Response) request {
+	t.Helper()
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var echoed request
+	require.NoError(t, json.Unmarshal(body, &echoed), string(body))
+	return echoed
+}
+
+func TestHTTP2_Cleartext(t *testing.T) {
+	restoreConfig(t)
+	config.LoadedConfiguration.MethodResponseCodeMap = map[string]int{}
+	app, _ := newApp(embed.FS{})
+	addr := serveHTTP2(t, newHTTP2Server(app, nil), false)
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+
+	r, err := http.NewRequest(http.MethodPost, "http://"+addr+"/h2c?responseCode=201", bytes.NewBufferString(`{"stream":1}`))
+	require.NoError(t, err)
+	r.Header.Add("X-Repeated", "first")
+	r.Header.Add("X-Repeated", "second")
+	resp, err := client.Do(r)
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/2.0", resp.Proto)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	request := echoed(t, resp)
+	assert.Equal(t, "HTTP/2.0", request.Proto)
+	assert.Equal(t, "/h2c", request.Path)
+	assert.JSONEq(t, `{"stream":1}`, string(request.Body))
+	assert.Equal(t, []string{"first", "second"}, request.Headers["X-Repeated"])
+}
+
+func TestHTTP2_ALPN(t *testing.T) {
+	restoreConfig(t)
+	config.LoadedConfiguration.TLSEnabled = true
+	config.LoadedConfiguration.TLSHostnames = []string{"127.0.0.1"}
+	app, tlsSetup := newApp(embed.FS{})
+	addr := serveHTTP2(t, newHTTP2Server(app, tlsSetup.config), true)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(tlsSetup.ca.Cert)
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: pool},
+		ForceAttemptHTTP2: true,
+	}}
+	resp, err := client.Get("https://" + addr + "/h2")
+	require.NoError(t, err)
+	assert.Equal(t, "h2", resp.TLS.NegotiatedProtocol)
+	assert.Equal(t, "HTTP/2.0", echoed(t, resp).Proto)
+}
+
+func TestRequestProtocol_HTTP1(t *testing.T) {
+	app := NewApp(embed.FS{})
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/h1", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1", echoed(t, resp).Proto)
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cosmoparrot/internal/config"
+	"errors"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// configuredListener tags the connections it accepts with its configuration,
+// so handlers can tell which listener a request came in on.
+type configuredListener struct {
+	net.Listener
+	config config.Listener
+}
+
+type configuredConn struct {
+	net.Conn
+	listener *config.Listener
+}
+
+func (l *configuredListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &configuredConn{Conn: conn, listener: &l.config}, nil
+}
+
+// newConfiguredListener listens on a listeners entry. Stale unix sockets of
+// previous runs are removed first.
+func newConfiguredListener(l config.Listener) (net.Listener, error) {
+	if l.Network == "unix" {
+		if err := removeStaleSocket(l.Address); err != nil {
+			return nil, err
+		}
+	}
+	ln, err := net.Listen(l.Network, l.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &configuredListener{Listener: ln, config: l}, nil
+}
+
+// removeStaleSocket removes the unix socket at path if no process listens on it
+// anymore. A socket that still accepts connections is not taken over.
+func removeStaleSocket(path string) error {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		_ = conn.Close()
+		return &net.OpError{Op: "listen", Net: "unix", Addr: &net.UnixAddr{Name: path, Net: "unix"}, Err: syscall.EADDRINUSE}
+	}
+	if errors.Is(err, syscall.ENOENT) {
+		return nil
+	}
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		return os.Remove(path)
+	}
+	return nil
+}
+
+// listenConfigured serves the app on the additional listeners in the background.
+func listenConfigured(app *fiber.App) {
+	for _, entry := range config.LoadedConfiguration.Listeners {
+		l, err := config.ParseListener(entry)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to listen")
+		}
+		ln, err := newConfiguredListener(l)
+		if err != nil {
+			log.Fatal().Err(err).Str("addr", l.String()).Msg("failed to listen")
+		}
+		event := log.Info().Str("addr", l.String())
+		if l.ResponseCode != 0 {
+			event.Int("responseCode", l.ResponseCode)
+		}
+		event.Msg("listening")
+		go func() {
+			if err := app.Listener(ln); err != nil {
+				log.Fatal().Err(err).Str("addr", l.String()).Msg("failed to listen")
+			}
+		}()
+	}
+}
+
+// requestListener returns the additional listener a request came in on, nil
+// for the other listeners.
+func requestListener(c *fiber.Ctx) *config.Listener {
+	if conn, ok := c.Context().Conn().(*configuredConn); ok {
+		return conn.listener
+	}
+	return nil
+}
+
+// listenerName returns the address of the additional listener a request came
+// in on, "" for the other listeners.
+func listenerName(c *fiber.Ctx) string {
+	if l := requestListener(c); l != nil {
+		return l.String()
+	}
+	return ""
+}
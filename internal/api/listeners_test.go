@@ -0,0 +1,96 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"cosmoparrot/internal/config"
+	"embed"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfiguredListeners(t *testing.T) {
+	restoreConfig(t)
+	config.LoadedConfiguration.MethodResponseCodeMap = map[string]int{}
+	app := NewApp(embed.FS{})
+	t.Cleanup(func() { _ = app.Shutdown() })
+
+	serve := func(l config.Listener) net.Listener {
+		ln, err := newConfiguredListener(l)
+		require.NoError(t, err)
+		go func() { _ = app.Listener(ln) }()
+		return ln
+	}
+	tcp := serve(config.Listener{Network: "tcp", Address: "127.0.0.1:0", ResponseCode: 503})
+	socket := filepath.Join(t.TempDir(), "consumer.sock")
+	serve(config.Listener{Network: "unix", Address: socket})
+
+	resp, err := http.Get("http://" + tcp.Addr().String() + "/consumer")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "127.0.0.1:0", echoed(t, resp).Listener)
+
+	// the query parameter still takes precedence
+	resp, err = http.Get("http://" + tcp.Addr().String() + "/consumer?responseCode=201")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// the listener's code replaces the method mapping
+	config.LoadedConfiguration.MethodResponseCodeMap = map[string]int{http.MethodPost: 202}
+	resp, err = http.Post("http://"+tcp.Addr().String()+"/consumer", "application/json", strings.NewReader("{}"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	resp, err = client.Get("http://cosmoparrot/consumer")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "unix:"+socket, echoed(t, resp).Listener)
+}
+
+func TestConfiguredListeners_StaleSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "stale.sock")
+	l := config.Listener{Network: "unix", Address: socket}
+
+	// a socket file left behind by a previous run
+	stale, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	ln, err := newConfiguredListener(l)
+	require.NoError(t, err)
+	assert.NoError(t, ln.Close())
+}
+
+func TestConfiguredListeners_LiveSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "live.sock")
+	l := config.Listener{Network: "unix", Address: socket}
+
+	// the socket of an instance that is still running
+	live, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = live.Close() })
+
+	_, err = newConfiguredListener(l)
+	require.ErrorIs(t, err, syscall.EADDRINUSE)
+	conn, err := net.Dial("unix", socket)
+	require.NoError(t, err, "the socket must not be taken over")
+	assert.NoError(t, conn.Close())
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cosmoparrot/internal/config"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// requestLogSampler decides which requests end up in the request log, so soak
+// tests keep some visibility without logging every request.
+type requestLogSampler struct {
+	seen        atomic.Uint64
+	window      atomic.Int64
+	windowCount atomic.Int64
+}
+
+// pathLogged applies the configured include and exclude patterns. It only
+// depends on the path, so it is checked before the request is handled.
+func pathLogged(p string) bool {
+	cfg := config.LoadedConfiguration
+	if len(cfg.RequestLogIncludePaths) > 0 && !matchesAnyPath(cfg.RequestLogIncludePaths, p) {
+		return false
+	}
+	return !matchesAnyPath(cfg.RequestLogExcludePaths, p)
+}
+
+// matchesAnyPath reports whether p matches one of the patterns. Patterns use
+// path.Match syntax; a trailing "/**" matches the prefix and all paths below it.
+func matchesAnyPath(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+			if p == prefix || strings.HasPrefix(p, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if matched, _ := path.Match(pattern, p); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// sample decides, once the status is known, whether a request is logged. Only
+// non-2xx responses are considered when requestLogErrorsOnly is set; of those,
+// every requestLogSampleRate-th is taken, at most requestLogRateLimit per second.
+func (s *requestLogSampler) sample(status int) bool {
+	cfg := config.LoadedConfiguration
+	if cfg.RequestLogErrorsOnly && status >= 200 && status < 300 {
+		return false
+	}
+
+	if rate := uint64(cfg.RequestLogSampleRate); rate > 1 && (s.seen.Add(1)-1)%rate != 0 {
+		return false
+	}
+
+	if limit := int64(cfg.RequestLogRateLimit); limit > 0 {
+		now := time.Now().Unix()
+		if window := s.window.Load(); window != now && s.window.CompareAndSwap(window, now) {
+			s.windowCount.Store(0)
+		}
+		if s.windowCount.Add(1) > limit {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bytes"
+	"cosmoparrot/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchesAnyPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/health", "/health", true},
+		{"/callbacks/*", "/callbacks/a", true},
+		{"/callbacks/*", "/callbacks/a/b", false},
+		{"/callbacks/**", "/callbacks/a/b", true},
+		{"/callbacks/**", "/callbacks", true},
+		{"/callbacks/**", "/callbacksx", false},
+		{"/*/events", "/orders/events", true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchesAnyPath([]string{tt.pattern}, tt.path), "%s ~ %s", tt.pattern, tt.path)
+	}
+}
+
+func TestRequestLogSampler(t *testing.T) {
+	original := config.LoadedConfiguration
+	defer func() { config.LoadedConfiguration = original }()
+
+	config.LoadedConfiguration.RequestLogSampleRate = 3
+	sampler := &requestLogSampler{}
+	var logged []bool
+	for range 6 {
+		logged = append(logged, sampler.sample(http.StatusOK))
+	}
+	assert.Equal(t, []bool{true, false, false, true, false, false}, logged)
+
+	config.LoadedConfiguration.RequestLogSampleRate = 1
+	config.LoadedConfiguration.RequestLogRateLimit = 2
+	sampler = &requestLogSampler{}
+	assert.True(t, sampler.sample(http.StatusOK))
+	assert.True(t, sampler.sample(http.StatusOK))
+	assert.False(t, sampler.sample(http.StatusOK))
+
+	config.LoadedConfiguration.RequestLogRateLimit = 0
+	config.LoadedConfiguration.RequestLogErrorsOnly = true
+	sampler = &requestLogSampler{}
+	assert.False(t, sampler.sample(http.StatusNoContent))
+	assert.True(t, sampler.sample(http.StatusInternalServerError))
+	assert.True(t, sampler.sample(http.StatusNotFound))
+}
+
+func TestRequestLogPathFilters(t *testing.T) {
+	original := config.LoadedConfiguration
+	defer func() { config.LoadedConfiguration = original }()
+	config.LoadedConfiguration.RequestLogIncludePaths = []string{"/callbacks/**"}
+	config.LoadedConfiguration.RequestLogExcludePaths = []string{"/callbacks/noisy"}
+
+	buf := captureLogs(t)
+	app := fiber.New()
+	app.Use(createNewLogHandler())
+	app.Use(func(c *fiber.Ctx) error { return c.SendStatus(http.StatusOK) })
+
+	for _, p := range []string{"/other", "/callbacks/noisy", "/callbacks/orders"} {
+		_, err := app.Test(httptest.NewRequest(http.MethodPost, p, nil), -1)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("\n")))
+	assert.Contains(t, buf.String(), `"path":"/callbacks/orders"`)
+}
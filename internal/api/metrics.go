@@ -0,0 +1,147 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cosmoparrot/internal/cache"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsPath = "/metrics"
+
+// metricsRegistry is a dedicated registry so that creating several apps (e.g. in
+// tests) does not register the same collectors twice.
+var metricsRegistry = prometheus.NewRegistry()
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cosmoparrot_http_requests_total",
+		Help: "Number of handled HTTP requests.",
+	}, []string{"route", "method", "status"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cosmoparrot_http_request_duration_seconds",
+		Help:    "Time spent handling HTTP requests, including injected delays.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	requestBytesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cosmoparrot_http_request_bytes_total",
+		Help: "Received request body bytes as announced by the Content-Length header.",
+	}, []string{"route", "method"})
+
+	responseBytesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cosmoparrot_http_response_bytes_total",
+		Help: "Sent response body bytes, excluding streamed responses.",
+	}, []string{"route", "method"})
+
+	devNullBytesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "cosmoparrot_devnull_bytes_total",
+		Help: "Request body bytes discarded by the devnull endpoint.",
+	})
+
+	devNullRequestsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "cosmoparrot_devnull_requests_total",
+		Help: "Requests handled by the devnull endpoint.",
+	})
+
+	slowlorisActiveStreams = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cosmoparrot_slowloris_active_streams",
+		Help: "Slowloris responses that are currently being streamed.",
+	})
+
+	sseActiveStreams = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cosmoparrot_sse_active_streams",
+		Help: "Server-sent event streams that are currently open.",
+	})
+
+	websocketActiveConnections = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cosmoparrot_websocket_active_connections",
+		Help: "WebSocket connections that are currently open.",
+	})
+)
+
+func init() {
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requestsTotal,
+		requestDuration,
+		requestBytesTotal,
+		responseBytesTotal,
+		devNullBytesTotal,
+		devNullRequestsTotal,
+		slowlorisActiveStreams,
+		sseActiveStreams,
+		websocketActiveConnections,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "cosmoparrot_store_keys",
+			Help: "Number of keys in the request store.",
+		}, func() float64 {
+			return float64(cache.Current.ItemCount())
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "cosmoparrot_store_size_bytes",
+			Help: "Size of the serialized requests held in the request store.",
+		}, storeSizeBytes),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "cosmoparrot_store_evictions_total",
+			Help: "Entries removed from the request store because they expired or were deleted.",
+		}, func() float64 {
+			return float64(cache.Evictions())
+		}),
+	)
+}
+
+func storeSizeBytes() float64 {
+	var size int
+	for _, item := range cache.Current.Items() {
+		if s, ok := item.Object.(string); ok {
+			size += len(s)
+		}
+	}
+	return float64(size)
+}
+
+// createMetricsHandler records request metrics for every route except the
+// metrics endpoint itself.
+func createMetricsHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Path() == metricsPath {
+			return c.Next()
+		}
+
+		start := time.Now()
+		err := c.Next()
+		status := responseStatus(c, err)
+
+		// fiber reuses the underlying buffers, so label values must be copied
+		route := strings.Clone(c.Route().Path)
+		method := strings.Clone(c.Method())
+		requestsTotal.WithLabelValues(route, method, strconv.Itoa(status)).Inc()
+		requestDuration.WithLabelValues(route, method).Observe(time.Since(start).Seconds())
+		if length := c.Request().Header.ContentLength(); length > 0 {
+			requestBytesTotal.WithLabelValues(route, method).Add(float64(length))
+		}
+		// reading the body of a streamed response would wait for the stream to end
+		if !c.Response().IsBodyStream() {
+			responseBytesTotal.WithLabelValues(route, method).Add(float64(len(c.Response().Body())))
+		}
+
+		return err
+	}
+}
+
+func handleGetMetrics() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
+}
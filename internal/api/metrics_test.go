@@ -0,0 +1,122 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bytes"
+	"cosmoparrot/internal/cache"
+	"cosmoparrot/internal/config"
+	"embed"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	c "github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrapeMetrics(t *testing.T) string {
+	t.Helper()
+	app := NewApp(embed.FS{})
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics_RequestCounters(t *testing.T) {
+	cache.Current = c.New(5*time.Minute, 10*time.Minute)
+	app := NewApp(embed.FS{})
+
+	r := httptest.NewRequest(http.MethodPost, "/metrics-test?responseCode=202", bytes.NewReader([]byte(`{"a":1}`)))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Request-Key", "metrics-key")
+	resp, err := app.Test(r, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	r = httptest.NewRequest(http.MethodPut, "/api/v1/devnull", bytes.NewReader(make([]byte, 1024)))
+	resp, err = app.Test(r, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	metrics := scrapeMetrics(t)
+	assert.Contains(t, metrics, `cosmoparrot_http_requests_total{method="POST",route="/",status="202"}`)
+	assert.Contains(t, metrics, `cosmoparrot_http_requests_total{method="PUT",route="/api/v1/devnull",status="200"}`)
+	assert.Contains(t, metrics, `cosmoparrot_http_request_duration_seconds_bucket{method="POST",route="/"`)
+	assert.Contains(t, metrics, `cosmoparrot_http_request_bytes_total{method="POST",route="/"}`)
+	assert.Contains(t, metrics, `cosmoparrot_devnull_bytes_total`)
+	assert.Contains(t, metrics, "cosmoparrot_store_keys 1")
+	assert.Contains(t, metrics, "cosmoparrot_store_evictions_total")
+	assert.Contains(t, metrics, "cosmoparrot_slowloris_active_streams 0")
+	// the metrics endpoint does not count itself
+	assert.NotContains(t, metrics, `route="/metrics"`)
+}
+
+func TestMetrics_Disabled(t *testing.T) {
+	original := config.LoadedConfiguration.MetricsEnabled
+	config.LoadedConfiguration.MetricsEnabled = false
+	defer func() { config.LoadedConfiguration.MetricsEnabled = original }()
+
+	app := NewApp(embed.FS{})
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.NoError(t, err)
+
+	// without metrics the path is handled by the echo handler
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"path":"/metrics"`)
+}
+
+func TestMetrics_StreamedResponses(t *testing.T) {
+	restoreConfig(t)
+	config.LoadedConfiguration.MetricsEnabled = true
+	app := NewApp(embed.FS{})
+	// app.Test waits for the whole response, so the timing is only observable
+	// on a real connection
+	addr := serveApp(t, app)
+
+	start := time.Now()
+	resp, err := http.Get("http://" + addr + "/api/v1/slowloris?duration=3&interval=1")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	_, err = io.ReadFull(resp.Body, make([]byte, 1))
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), time.Second, "the first byte must arrive before the stream ends")
+}
+
+// devNullBytes returns the value of the devnull byte counter.
+func devNullBytes(t *testing.T) float64 {
+	t.Helper()
+	match := regexp.MustCompile(`(?m)^cosmoparrot_devnull_bytes_total (\S+)$`).FindStringSubmatch(scrapeMetrics(t))
+	require.NotNil(t, match)
+	value, err := strconv.ParseFloat(match[1], 64)
+	require.NoError(t, err)
+	return value
+}
+
+func TestMetrics_DevNullChunkedBytes(t *testing.T) {
+	restoreConfig(t)
+	config.LoadedConfiguration.MetricsEnabled = true
+	app := NewApp(embed.FS{})
+	// app.Test cannot send chunked bodies
+	addr := serveApp(t, app)
+
+	before := devNullBytes(t)
+	// readers of unknown length are sent chunked
+	resp, err := http.Post("http://"+addr+"/api/v1/devnull", "text/plain", io.MultiReader(bytes.NewReader(make([]byte, 1000))))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, before+1000, devNullBytes(t))
+}
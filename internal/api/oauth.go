@@ -0,0 +1,202 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cosmoparrot/internal/auth"
+	"cosmoparrot/internal/config"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	oauthTokenPath = "/oauth2/token"
+	oauthJwksPath  = "/.well-known/jwks.json"
+)
+
+// oauthErrorStatus maps the error codes of RFC 6749, section 5.2 and the
+// common server errors to the status they are answered with.
+var oauthErrorStatus = map[string]int{
+	"invalid_request":         fiber.StatusBadRequest,
+	"invalid_client":          fiber.StatusUnauthorized,
+	"invalid_grant":           fiber.StatusBadRequest,
+	"unauthorized_client":     fiber.StatusBadRequest,
+	"unsupported_grant_type":  fiber.StatusBadRequest,
+	"invalid_scope":           fiber.StatusBadRequest,
+	"server_error":            fiber.StatusInternalServerError,
+	"temporarily_unavailable": fiber.StatusServiceUnavailable,
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+type oauthError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// tokenIssuer is a minimal OAuth2 authorization server that issues JWTs for
+// the client credentials grant, so producers can be tested without an IdP.
+type tokenIssuer struct {
+	key *auth.SigningKey
+}
+
+// newTokenIssuer uses the configured signing key or generates one, which is
+// then only valid until the next restart.
+func newTokenIssuer() (*tokenIssuer, error) {
+	cfg := config.LoadedConfiguration
+	if cfg.OAuthSigningKeyFile != "" {
+		key, err := auth.LoadSigningKey(cfg.OAuthSigningKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return &tokenIssuer{key: key}, nil
+	}
+
+	key, err := auth.GenerateSigningKey()
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Str("kid", key.Kid).Msg("generated OAuth2 signing key")
+	return &tokenIssuer{key: key}, nil
+}
+
+func (i *tokenIssuer) handleGetJwks(c *fiber.Ctx) error {
+	keys, err := i.key.KeySet()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to encode JWKS")
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	return c.JSON(keys)
+}
+
+// handleToken issues tokens for the client credentials grant. Failures and
+// slow issuance can be requested per request with the "error", "responseDelay"
+// and "expiresIn" query parameters.
+func (i *tokenIssuer) handleToken(c *fiber.Ctx) error {
+	// token responses must not be cached, see RFC 6749, section 5.1
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	if delay := getResponseDelay(c); delay > 0 {
+		time.Sleep(delay)
+	}
+	if code := queryCaseInsensitive(c, "error"); code != "" {
+		return tokenError(c, code, "error requested by the client")
+	}
+
+	if grantType := c.FormValue("grant_type"); grantType != "client_credentials" {
+		return tokenError(c, "unsupported_grant_type", "only client_credentials is supported")
+	}
+	clientID, ok := authenticateClient(c)
+	if !ok {
+		c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="`+authRealm+`"`)
+		return tokenError(c, "invalid_client", "client authentication failed")
+	}
+
+	cfg := config.LoadedConfiguration
+	expiry := cfg.OAuthTokenExpiry
+	if raw := queryCaseInsensitive(c, "expiresIn"); raw != "" {
+		// negative values issue tokens that are already expired
+		seconds, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return tokenError(c, "invalid_request", "expiresIn must be a number of seconds")
+		}
+		expiry = time.Duration(seconds) * time.Second
+	}
+
+	scope := strings.Join(strings.Fields(c.FormValue("scope")), " ")
+	token, err := i.key.Sign(i.claims(c, clientID, scope, expiry))
+	if err != nil {
+		log.Error().Err(err).Msg("failed to sign token")
+		return tokenError(c, "server_error", "")
+	}
+
+	return c.JSON(tokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(expiry.Seconds()),
+		Scope:       scope,
+	})
+}
+
+func (i *tokenIssuer) claims(c *fiber.Ctx, clientID string, scope string, expiry time.Duration) jwt.MapClaims {
+	cfg := config.LoadedConfiguration
+	now := time.Now()
+
+	claims := jwt.MapClaims{}
+	// configured claims come first, so they cannot replace the registered ones
+	for _, claim := range cfg.OAuthClaims {
+		if name, value, found := strings.Cut(claim, "="); found {
+			claims[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+
+	issuer := cfg.OAuthIssuer
+	if issuer == "" {
+		issuer = c.BaseURL()
+	}
+	claims["iss"] = issuer
+	claims["sub"] = clientID
+	claims["client_id"] = clientID
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(expiry).Unix()
+	claims["jti"] = newTokenID()
+	if cfg.OAuthAudience != "" {
+		claims["aud"] = cfg.OAuthAudience
+	}
+	if scope != "" {
+		claims["scope"] = scope
+	}
+	return claims
+}
+
+// authenticateClient accepts client_secret_basic and client_secret_post. If no
+// clients are configured, every client id is accepted.
+func authenticateClient(c *fiber.Ctx) (string, bool) {
+	clientID, secret, ok := basicCredentials(c)
+	if !ok {
+		clientID, secret = c.FormValue("client_id"), c.FormValue("client_secret")
+	}
+	if clientID == "" {
+		return "", false
+	}
+
+	clients := config.LoadedConfiguration.OAuthClients
+	if len(clients) == 0 {
+		return clientID, true
+	}
+	for _, client := range clients {
+		id, expected, _ := strings.Cut(client, ":")
+		if id == clientID && equalSecret(secret, expected) {
+			return clientID, true
+		}
+	}
+	return "", false
+}
+
+func tokenError(c *fiber.Ctx, code string, description string) error {
+	status, ok := oauthErrorStatus[code]
+	if !ok {
+		status = fiber.StatusBadRequest
+	}
+	return c.Status(status).JSON(oauthError{Error: code, ErrorDescription: description})
+}
+
+func newTokenID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
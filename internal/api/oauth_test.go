@@ -0,0 +1,122 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cosmoparrot/internal/auth"
+	"cosmoparrot/internal/config"
+	"embed"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newOAuthTestApp(t *testing.T) *fiber.App {
+	restoreConfig(t)
+	config.LoadedConfiguration.OAuthEnabled = true
+	config.LoadedConfiguration.OAuthIssuer = "https://parrot.example"
+	config.LoadedConfiguration.OAuthAudience = "consumer"
+	config.LoadedConfiguration.OAuthTokenExpiry = time.Minute
+	config.LoadedConfiguration.OAuthClients = []string{"producer:secret"}
+	config.LoadedConfiguration.OAuthClaims = []string{"tenant=horizon", "sub=ignored"}
+	return NewApp(embed.FS{})
+}
+
+func requestToken(t *testing.T, app *fiber.App, query string, form url.Values) (*http.Response, []byte) {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, oauthTokenPath+query, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := app.Test(r, -1)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, body
+}
+
+func fetchVerifier(t *testing.T, app *fiber.App) *auth.Verifier {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, oauthJwksPath, nil), -1)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	keys, err := auth.ParseKeySet(body)
+	require.NoError(t, err)
+	return &auth.Verifier{Keys: keys, Issuer: "https://parrot.example", Audience: "consumer"}
+}
+
+func TestOAuthToken_ClientCredentials(t *testing.T) {
+	app := newOAuthTestApp(t)
+
+	resp, body := requestToken(t, app, "", url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {"producer"},
+		"client_secret": {"secret"},
+		"scope":         {"events:write  events:read"},
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+
+	var token tokenResponse
+	require.NoError(t, json.Unmarshal(body, &token))
+	assert.Equal(t, "Bearer", token.TokenType)
+	assert.Equal(t, int64(60), token.ExpiresIn)
+	assert.Equal(t, "events:write events:read", token.Scope)
+
+	claims, err := fetchVerifier(t, app).Verify(token.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "producer", claims["sub"])
+	assert.Equal(t, "horizon", claims["tenant"])
+	assert.Equal(t, "events:write events:read", claims["scope"])
+}
+
+func TestOAuthToken_BasicClientAuthentication(t *testing.T) {
+	app := newOAuthTestApp(t)
+
+	r := httptest.NewRequest(http.MethodPost, oauthTokenPath, strings.NewReader("grant_type=client_credentials"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.SetBasicAuth("producer", "secret")
+	assert.Equal(t, http.StatusOK, statusOf(t, app, r))
+
+	r = httptest.NewRequest(http.MethodPost, oauthTokenPath, strings.NewReader("grant_type=client_credentials"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.SetBasicAuth("producer", "wrong")
+	resp, err := app.Test(r, -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("WWW-Authenticate"))
+}
+
+func TestOAuthToken_Failures(t *testing.T) {
+	app := newOAuthTestApp(t)
+	credentials := url.Values{"grant_type": {"client_credentials"}, "client_id": {"producer"}, "client_secret": {"secret"}}
+
+	resp, body := requestToken(t, app, "?error=temporarily_unavailable", credentials)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Contains(t, string(body), `"error":"temporarily_unavailable"`)
+
+	resp, body = requestToken(t, app, "", url.Values{"grant_type": {"password"}, "client_id": {"producer"}})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, string(body), `"error":"unsupported_grant_type"`)
+
+	start := time.Now()
+	resp, body = requestToken(t, app, "?responseDelay=100&expiresIn=-60", credentials)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var token tokenResponse
+	require.NoError(t, json.Unmarshal(body, &token))
+	_, err := fetchVerifier(t, app).Verify(token.AccessToken)
+	assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+}
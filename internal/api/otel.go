@@ -7,24 +7,45 @@ package api
 import (
 	"context"
 	"cosmoparrot/internal/config"
+	"cosmoparrot/internal/logging"
+	"strings"
 
+	"github.com/gofiber/contrib/otelfiber/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	otelprom "go.opentelemetry.io/contrib/bridges/prometheus"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log/global"
 	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func initTracerProvider() func() {
-	ctx := context.Background()
+// Span attributes describing how the echo handler treated a request.
+const (
+	attrStoreKey            = attribute.Key("cosmoparrot.store.key")
+	attrStoreRequests       = attribute.Key("cosmoparrot.store.requests")
+	attrRequestBodySize     = attribute.Key("cosmoparrot.request.body_size")
+	attrRequestBodyMirrored = attribute.Key("cosmoparrot.request.body_mirrored")
+	attrResponseCode        = attribute.Key("cosmoparrot.response.code")
+	attrResponseDelay       = attribute.Key("cosmoparrot.response.delay_ms")
+	attrResponseSize        = attribute.Key("cosmoparrot.response.size")
+	attrChaos               = attribute.Key("cosmoparrot.response.chaos")
+)
 
-	exporter, err := otlptracegrpc.New(ctx)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to initialize OTel OTLP trace exporter; tracing disabled")
-		return func() {}
-	}
+// initTelemetry sets up the OpenTelemetry providers enabled in the configuration.
+// All providers share the same resource. The returned function flushes and shuts
+// them down.
+func initTelemetry() func() {
+	ctx := context.Background()
 
 	res, err := resource.New(ctx,
 		resource.WithAttributes(
@@ -32,18 +53,51 @@ func initTracerProvider() func() {
 		),
 	)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to initialize OTel resource; tracing disabled")
+		log.Error().Err(err).Msg("Failed to initialize OTel resource; telemetry disabled")
+		return func() {}
+	}
+
+	output := &exporterOutput{}
+	shutdowns := []func(){initTracerProvider(ctx, res, output)}
+	if config.LoadedConfiguration.OTelMetricsEnabled {
+		shutdowns = append(shutdowns, initMeterProvider(ctx, res, output))
+	}
+	if config.LoadedConfiguration.OTelLogsEnabled {
+		shutdowns = append(shutdowns, initLoggerProvider(ctx, res, output))
+	}
+
+	return func() {
+		for _, shutdown := range shutdowns {
+			shutdown()
+		}
+		if err := output.Close(); err != nil {
+			log.Error().Err(err).Msg("Failed to close OTel exporter file")
+		}
+	}
+}
+
+func initTracerProvider(ctx context.Context, res *resource.Resource, output *exporterOutput) func() {
+	exporter, err := newTraceExporter(ctx, output)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to initialize OTel trace exporter; tracing disabled")
+		return func() {}
+	}
+
+	sampler, err := newSampler(config.LoadedConfiguration.OTelSampler, config.LoadedConfiguration.OTelSamplerRatio)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to initialize OTel sampler; tracing disabled")
 		return func() {}
 	}
 
 	tracerProvider := sdktrace.NewTracerProvider(
 		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sampler),
 		sdktrace.WithResource(res),
 	)
 	otel.SetTracerProvider(tracerProvider)
-	otel.SetTextMapPropagator(propagation.TraceContext{})
+	otel.SetTextMapPropagator(newPropagator(config.LoadedConfiguration.OTelPropagators))
 
-	log.Info().Msg("OpenTelemetry tracing enabled")
+	log.Info().Str("protocol", exporterProtocol("TRACES")).Msg("OpenTelemetry tracing enabled")
 
 	return func() {
 		if shutdownErr := tracerProvider.Shutdown(ctx); shutdownErr != nil {
@@ -51,3 +105,148 @@ func initTracerProvider() func() {
 		}
 	}
 }
+
+// newPropagator combines the given propagation formats into one propagator.
+// Incoming requests are accepted in any of the formats.
+func newPropagator(names []string) propagation.TextMapPropagator {
+	var propagators []propagation.TextMapPropagator
+	for _, name := range names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "tracecontext":
+			propagators = append(propagators, propagation.TraceContext{})
+		case "baggage":
+			propagators = append(propagators, propagation.Baggage{})
+		case "b3":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case "b3multi":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case "jaeger":
+			propagators = append(propagators, jaeger.Jaeger{})
+		default:
+			log.Warn().Str("propagator", name).Msg("Ignoring unknown OTel propagator")
+		}
+	}
+
+	if len(propagators) == 0 {
+		return propagation.TraceContext{}
+	}
+	return propagation.NewCompositeTextMapPropagator(propagators...)
+}
+
+// traceContextPropagator reads the trace context of incoming requests in every
+// format producers are known to send, independent of the tracing configuration.
+var traceContextPropagator = propagation.NewCompositeTextMapPropagator(
+	propagation.TraceContext{},
+	b3.New(),
+	jaeger.Jaeger{},
+)
+
+// extractTraceContext returns the trace and span id propagated by the caller,
+// or empty strings if the request carries no valid trace context.
+func extractTraceContext(c *fiber.Ctx) (string, string) {
+	return traceContextFromHeaders(c.GetReqHeaders())
+}
+
+// traceContextFromHeaders returns the trace and span id propagated in the
+// given headers, which need to be in canonical form.
+func traceContextFromHeaders(headers map[string][]string) (string, string) {
+	carrier := propagation.HeaderCarrier(headers)
+	spanContext := trace.SpanContextFromContext(traceContextPropagator.Extract(context.Background(), carrier))
+	if !spanContext.IsValid() {
+		return "", ""
+	}
+	return spanContext.TraceID().String(), spanContext.SpanID().String()
+}
+
+// normalizeTraceID brings a trace id into the 32 hex digit form that is stored.
+// 64-bit B3 trace ids are left-padded with zeros like the propagators do.
+// Returns "" for invalid ids.
+func normalizeTraceID(raw string) string {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if len(raw) == 16 {
+		raw = strings.Repeat("0", 16) + raw
+	}
+	traceID, err := trace.TraceIDFromHex(raw)
+	if err != nil {
+		return ""
+	}
+	return traceID.String()
+}
+
+// newTracingMiddleware traces requests, except browsers loading the web UI, and
+// leaves the span context for the request log.
+func newTracingMiddleware() []fiber.Handler {
+	return []fiber.Handler{
+		otelfiber.Middleware(otelfiber.WithNext(func(c *fiber.Ctx) bool {
+			return isWebUIRequest(c) || isTraced(c)
+		})),
+		func(c *fiber.Ctx) error {
+			if !isTraced(c) {
+				c.Locals(spanContextKey, trace.SpanContextFromContext(c.UserContext()))
+			}
+			return c.Next()
+		},
+	}
+}
+
+// isTraced reports whether the tracing middleware already ran for a request,
+// e.g. on the /api group before an unknown path falls through to the echo handler.
+func isTraced(c *fiber.Ctx) bool {
+	return c.Locals(spanContextKey) != nil
+}
+
+// newMetricProducer exposes the Prometheus metrics of the metrics endpoint to
+// OpenTelemetry readers, so both report the same request and store metrics.
+func newMetricProducer() sdkmetric.Producer {
+	return otelprom.NewMetricProducer(otelprom.WithGatherer(metricsRegistry))
+}
+
+func initMeterProvider(ctx context.Context, res *resource.Resource, output *exporterOutput) func() {
+	exporter, err := newMetricExporter(ctx, output)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to initialize OTel metric exporter; metrics export disabled")
+		return func() {}
+	}
+
+	meterProvider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithProducer(newMetricProducer()))),
+		sdkmetric.WithResource(res),
+	)
+	otel.SetMeterProvider(meterProvider)
+
+	log.Info().Msg("OpenTelemetry metrics enabled")
+
+	return func() {
+		if shutdownErr := meterProvider.Shutdown(ctx); shutdownErr != nil {
+			log.Error().Err(shutdownErr).Msg("Failed to shutdown OTel meter provider")
+		}
+	}
+}
+
+func initLoggerProvider(ctx context.Context, res *resource.Resource, output *exporterOutput) func() {
+	exporter, err := newLogExporter(ctx, output)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to initialize OTel log exporter; log export disabled")
+		return func() {}
+	}
+
+	loggerProvider := sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+		sdklog.WithResource(res),
+	)
+	global.SetLoggerProvider(loggerProvider)
+
+	// Keep writing to the configured output and additionally forward every record.
+	previous := log.Logger
+	otelWriter := newOTelLogWriter(loggerProvider.Logger(config.LoadedConfiguration.OTelServiceName))
+	log.Logger = log.Output(zerolog.MultiLevelWriter(logging.Output(), otelWriter))
+
+	log.Info().Msg("OpenTelemetry log export enabled")
+
+	return func() {
+		log.Logger = previous
+		if shutdownErr := loggerProvider.Shutdown(ctx); shutdownErr != nil {
+			log.Error().Err(shutdownErr).Msg("Failed to shutdown OTel logger provider")
+		}
+	}
+}
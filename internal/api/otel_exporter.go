@@ -0,0 +1,153 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"cosmoparrot/internal/config"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	protocolGRPC         = "grpc"
+	protocolHTTPProtobuf = "http/protobuf"
+	protocolStdout       = "stdout"
+	protocolFile         = "file"
+)
+
+// exporterProtocol returns the exporter protocol for a signal ("TRACES", "METRICS"
+// or "LOGS"). The configuration takes precedence over the standard
+// OTEL_EXPORTER_OTLP_<SIGNAL>_PROTOCOL and OTEL_EXPORTER_OTLP_PROTOCOL variables.
+func exporterProtocol(signal string) string {
+	for _, protocol := range []string{
+		config.LoadedConfiguration.OTelExporterProtocol,
+		os.Getenv("OTEL_EXPORTER_OTLP_" + signal + "_PROTOCOL"),
+		os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"),
+	} {
+		if protocol = strings.ToLower(strings.TrimSpace(protocol)); protocol != "" {
+			return protocol
+		}
+	}
+	return protocolGRPC
+}
+
+// exporterOutput is where the stdout and file exporters write to. The file is
+// opened once and shared by all signals.
+type exporterOutput struct {
+	file *os.File
+}
+
+func (o *exporterOutput) writer(protocol string) (io.Writer, error) {
+	if protocol != protocolFile {
+		return os.Stdout, nil
+	}
+	if o.file == nil {
+		path := config.LoadedConfiguration.OTelExporterFile
+		if path == "" {
+			return nil, fmt.Errorf("the file exporter requires otelExporterFile to be set")
+		}
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		o.file = file
+	}
+	return o.file, nil
+}
+
+func (o *exporterOutput) Close() error {
+	if o.file == nil {
+		return nil
+	}
+	return o.file.Close()
+}
+
+func newTraceExporter(ctx context.Context, output *exporterOutput) (sdktrace.SpanExporter, error) {
+	switch protocol := exporterProtocol("TRACES"); protocol {
+	case protocolGRPC:
+		return otlptracegrpc.New(ctx)
+	case protocolHTTPProtobuf:
+		return otlptracehttp.New(ctx)
+	case protocolStdout, protocolFile:
+		out, err := output.writer(protocol)
+		if err != nil {
+			return nil, err
+		}
+		return stdouttrace.New(stdouttrace.WithWriter(out))
+	default:
+		return nil, fmt.Errorf("unsupported exporter protocol %q", protocol)
+	}
+}
+
+func newMetricExporter(ctx context.Context, output *exporterOutput) (sdkmetric.Exporter, error) {
+	switch protocol := exporterProtocol("METRICS"); protocol {
+	case protocolGRPC:
+		return otlpmetricgrpc.New(ctx)
+	case protocolHTTPProtobuf:
+		return otlpmetrichttp.New(ctx)
+	case protocolStdout, protocolFile:
+		out, err := output.writer(protocol)
+		if err != nil {
+			return nil, err
+		}
+		return stdoutmetric.New(stdoutmetric.WithWriter(out))
+	default:
+		return nil, fmt.Errorf("unsupported exporter protocol %q", protocol)
+	}
+}
+
+func newLogExporter(ctx context.Context, output *exporterOutput) (sdklog.Exporter, error) {
+	switch protocol := exporterProtocol("LOGS"); protocol {
+	case protocolGRPC:
+		return otlploggrpc.New(ctx)
+	case protocolHTTPProtobuf:
+		return otlploghttp.New(ctx)
+	case protocolStdout, protocolFile:
+		out, err := output.writer(protocol)
+		if err != nil {
+			return nil, err
+		}
+		return stdoutlog.New(stdoutlog.WithWriter(out))
+	default:
+		return nil, fmt.Errorf("unsupported exporter protocol %q", protocol)
+	}
+}
+
+// newSampler builds the configured trace sampler. The names follow the values of
+// the standard OTEL_TRACES_SAMPLER variable.
+func newSampler(name string, ratio float64) (sdktrace.Sampler, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "always_on":
+		return sdktrace.AlwaysSample(), nil
+	case "always_off":
+		return sdktrace.NeverSample(), nil
+	case "traceidratio":
+		return sdktrace.TraceIDRatioBased(ratio), nil
+	case "parentbased_always_on", "":
+		return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+	case "parentbased_always_off":
+		return sdktrace.ParentBased(sdktrace.NeverSample()), nil
+	case "parentbased_traceidratio":
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio)), nil
+	default:
+		return nil, fmt.Errorf("unsupported sampler %q", name)
+	}
+}
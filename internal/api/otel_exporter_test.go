@@ -0,0 +1,74 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"cosmoparrot/internal/config"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+)
+
+func TestExporterProtocol(t *testing.T) {
+	original := config.LoadedConfiguration.OTelExporterProtocol
+	defer func() { config.LoadedConfiguration.OTelExporterProtocol = original }()
+
+	config.LoadedConfiguration.OTelExporterProtocol = ""
+	assert.Equal(t, protocolGRPC, exporterProtocol("TRACES"))
+
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+	assert.Equal(t, protocolHTTPProtobuf, exporterProtocol("TRACES"))
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "grpc")
+	assert.Equal(t, protocolGRPC, exporterProtocol("TRACES"))
+	assert.Equal(t, protocolHTTPProtobuf, exporterProtocol("METRICS"))
+
+	config.LoadedConfiguration.OTelExporterProtocol = "stdout"
+	assert.Equal(t, protocolStdout, exporterProtocol("TRACES"))
+}
+
+func TestNewSampler(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+	}{
+		{"always_on", "AlwaysOnSampler"},
+		{"always_off", "AlwaysOffSampler"},
+		{"traceidratio", "TraceIDRatioBased{0.25}"},
+		{"", "ParentBased{root:AlwaysOnSampler"},
+		{"parentbased_traceidratio", "ParentBased{root:TraceIDRatioBased{0.25}"},
+	}
+
+	for _, tt := range tests {
+		sampler, err := newSampler(tt.name, 0.25)
+		assert.NoError(t, err)
+		assert.Contains(t, sampler.Description(), tt.description)
+	}
+
+	_, err := newSampler("sometimes", 0.25)
+	assert.Error(t, err)
+}
+
+func TestInitTelemetry_FileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.json")
+	original := config.LoadedConfiguration
+	defer func() { config.LoadedConfiguration = original }()
+	config.LoadedConfiguration.OTelExporterProtocol = "file"
+	config.LoadedConfiguration.OTelExporterFile = path
+	config.LoadedConfiguration.OTelSampler = "always_on"
+
+	shutdown := initTelemetry()
+	_, span := otel.Tracer("test").Start(context.Background(), "file-exported-span")
+	span.End()
+	shutdown()
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "file-exported-span")
+}
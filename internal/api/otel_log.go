@@ -0,0 +1,98 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	otellog "go.opentelemetry.io/otel/log"
+)
+
+// otelLogWriter bridges zerolog to OpenTelemetry by turning every JSON encoded
+// zerolog event into a log record. Fields other than level, time and message
+// become record attributes.
+type otelLogWriter struct {
+	logger otellog.Logger
+}
+
+func newOTelLogWriter(logger otellog.Logger) *otelLogWriter {
+	return &otelLogWriter{logger: logger}
+}
+
+func (w *otelLogWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+func (w *otelLogWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(p, &fields); err != nil {
+		return 0, err
+	}
+
+	// events written regardless of the log level carry their level as a field
+	if level == zerolog.NoLevel {
+		if value, ok := fields[zerolog.LevelFieldName].(string); ok {
+			if parsed, err := zerolog.ParseLevel(value); err == nil {
+				level = parsed
+			}
+		}
+	}
+
+	var record otellog.Record
+	record.SetTimestamp(time.Now())
+	record.SetObservedTimestamp(time.Now())
+	record.SetSeverity(otelSeverity(level))
+	record.SetSeverityText(level.String())
+
+	for k, v := range fields {
+		switch k {
+		case zerolog.LevelFieldName, zerolog.TimestampFieldName:
+		case zerolog.MessageFieldName:
+			record.SetBody(otellog.StringValue(fmt.Sprint(v)))
+		default:
+			record.AddAttributes(otellog.KeyValue{Key: k, Value: otelLogValue(v)})
+		}
+	}
+
+	w.logger.Emit(context.Background(), record)
+	return len(p), nil
+}
+
+func otelLogValue(v any) otellog.Value {
+	switch value := v.(type) {
+	case string:
+		return otellog.StringValue(value)
+	case bool:
+		return otellog.BoolValue(value)
+	case float64:
+		return otellog.Float64Value(value)
+	default:
+		encoded, _ := json.Marshal(value)
+		return otellog.StringValue(string(encoded))
+	}
+}
+
+func otelSeverity(level zerolog.Level) otellog.Severity {
+	switch level {
+	case zerolog.TraceLevel:
+		return otellog.SeverityTrace
+	case zerolog.DebugLevel:
+		return otellog.SeverityDebug
+	case zerolog.InfoLevel:
+		return otellog.SeverityInfo
+	case zerolog.WarnLevel:
+		return otellog.SeverityWarn
+	case zerolog.ErrorLevel:
+		return otellog.SeverityError
+	case zerolog.FatalLevel, zerolog.PanicLevel:
+		return otellog.SeverityFatal
+	default:
+		return otellog.SeverityUndefined
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/contrib/otelfiber/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type recordingLogExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *recordingLogExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *recordingLogExporter) Shutdown(context.Context) error   { return nil }
+func (e *recordingLogExporter) ForceFlush(context.Context) error { return nil }
+
+func TestMetricProducer_ExportsRequestMetrics(t *testing.T) {
+	app := NewApp(embed.FS{})
+	_, err := app.Test(httptest.NewRequest(http.MethodGet, "/otel-metrics", nil))
+	assert.NoError(t, err)
+
+	reader := sdkmetric.NewManualReader(sdkmetric.WithProducer(newMetricProducer()))
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	var collected metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(context.Background(), &collected))
+
+	names := map[string]bool{}
+	for _, scope := range collected.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			names[m.Name] = true
+		}
+	}
+	assert.True(t, names["cosmoparrot_http_requests_total"])
+	assert.True(t, names["cosmoparrot_http_request_duration_seconds"])
+	assert.True(t, names["cosmoparrot_store_keys"])
+}
+
+func TestOTelLogWriter(t *testing.T) {
+	exporter := &recordingLogExporter{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))
+	defer provider.Shutdown(context.Background())
+
+	logger := zerolog.New(newOTelLogWriter(provider.Logger("test")))
+	logger.Warn().Str("key", "store-key").Int("count", 3).Msg("something happened")
+
+	assert.Len(t, exporter.records, 1)
+	record := exporter.records[0]
+	assert.Equal(t, "something happened", record.Body().AsString())
+	assert.Equal(t, otellog.SeverityWarn, record.Severity())
+	assert.Equal(t, "warn", record.SeverityText())
+
+	attributes := map[string]otellog.Value{}
+	record.WalkAttributes(func(kv otellog.KeyValue) bool {
+		attributes[kv.Key] = kv.Value
+		return true
+	})
+	assert.Equal(t, "store-key", attributes["key"].AsString())
+	assert.Equal(t, float64(3), attributes["count"].AsFloat64())
+	assert.NotContains(t, attributes, "level")
+
+	// request logs are written without a level and carry it as a field
+	logger.WithLevel(zerolog.NoLevel).Str(zerolog.LevelFieldName, zerolog.LevelInfoValue).Msg("request received")
+	assert.Len(t, exporter.records, 2)
+	assert.Equal(t, otellog.SeverityInfo, exporter.records[1].Severity())
+}
+
+func TestHandleAnyRequest_SpanAttributesAndEvents(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	app := fiber.New()
+	app.Use(otelfiber.Middleware(otelfiber.WithTracerProvider(provider)))
+	app.Use(handleAnyRequest)
+
+	r := httptest.NewRequest(http.MethodPost, "/traced?responseDelay=10&responseSize=5&responseCode=201", bytes.NewReader([]byte(`{"a":1}`)))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Request-Key", "traced-key")
+	_, err := app.Test(r, -1)
+	assert.NoError(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+
+	attributes := map[attribute.Key]attribute.Value{}
+	for _, kv := range spans[0].Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+	assert.Equal(t, "traced-key", attributes[attrStoreKey].AsString())
+	assert.Equal(t, int64(201), attributes[attrResponseCode].AsInt64())
+	assert.Equal(t, int64(10), attributes[attrResponseDelay].AsInt64())
+	assert.Equal(t, int64(5), attributes[attrResponseSize].AsInt64())
+	assert.Equal(t, int64(7), attributes[attrRequestBodySize].AsInt64())
+	assert.True(t, attributes[attrRequestBodyMirrored].AsBool())
+
+	var events []string
+	for _, event := range spans[0].Events() {
+		events = append(events, event.Name)
+	}
+	assert.Equal(t, []string{"store write", "response delay start", "response delay end"}, events)
+}
+
+func TestTracingMiddleware_SingleSpanForUnknownAPIPaths(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	// the middleware is registered on the /api group and for the echo handler,
+	// like in newApp
+	tracingMiddleware := newTracingMiddleware()
+	app := fiber.New()
+	app.Group("/api", tracingMiddleware...).Get("/v1/known", func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusNoContent)
+	})
+	for _, handler := range tracingMiddleware {
+		app.Use(handler)
+	}
+	app.Use(handleAnyRequest)
+
+	for _, path := range []string{"/api/v1/known", "/api/v1/unknown", "/echo"} {
+		_, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil), -1)
+		assert.NoError(t, err)
+	}
+	assert.Len(t, recorder.Ended(), 3)
+}
+
+func TestNewPropagator(t *testing.T) {
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01},
+		SpanID:     trace.SpanID{0x02},
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	tests := []struct {
+		names  []string
+		header string
+	}{
+		{[]string{"tracecontext"}, "traceparent"},
+		{[]string{"b3"}, "b3"},
+		{[]string{"b3multi"}, "x-b3-traceid"},
+		{[]string{"jaeger"}, "uber-trace-id"},
+		{[]string{"unknown"}, "traceparent"},
+	}
+
+	for _, tt := range tests {
+		carrier := propagation.MapCarrier{}
+		newPropagator(tt.names).Inject(ctx, carrier)
+		assert.NotEmpty(t, carrier.Get(tt.header), "propagators %v", tt.names)
+	}
+
+	// all configured formats are accepted for incoming requests
+	carrier := propagation.MapCarrier{"x-b3-traceid": "0100000000000000000000000000000a", "x-b3-spanid": "0200000000000000", "x-b3-sampled": "1"}
+	extracted := trace.SpanContextFromContext(newPropagator([]string{"tracecontext", "b3multi"}).Extract(context.Background(), carrier))
+	assert.Equal(t, "0100000000000000000000000000000a", extracted.TraceID().String())
+}
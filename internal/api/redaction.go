@@ -0,0 +1,70 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cosmoparrot/internal/config"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+)
+
+const redactedValue = "[REDACTED]"
+
+const redactionModeHash = "hash"
+
+// processRedactionKey keys the hashes unless redactionHashKey is set, so they
+// can only be compared within the same process.
+var processRedactionKey = newProcessRedactionKey()
+
+func newProcessRedactionKey() []byte {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return key
+}
+
+func redactionKey() []byte {
+	if key := config.LoadedConfiguration.RedactionHashKey; key != "" {
+		return []byte(key)
+	}
+	return processRedactionKey
+}
+
+func isRedactedHeader(name string) bool {
+	return slices.ContainsFunc(config.LoadedConfiguration.RedactedHeaders, func(h string) bool {
+		return strings.EqualFold(h, name)
+	})
+}
+
+// redactValue replaces a sensitive header value. In hash mode the value is
+// replaced by its HMAC-SHA256, so captured values can still be compared without
+// being disclosed. The key keeps short secrets from being brute-forced.
+func redactValue(value string) string {
+	if strings.EqualFold(config.LoadedConfiguration.RedactionMode, redactionModeHash) {
+		mac := hmac.New(sha256.New, redactionKey())
+		mac.Write([]byte(value))
+		return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil))
+	}
+	return redactedValue
+}
+
+// redactHeaders redacts the values of all configured headers in place and
+// returns the headers for convenience.
+func redactHeaders(headers map[string][]string) map[string][]string {
+	for name, values := range headers {
+		if !isRedactedHeader(name) {
+			continue
+		}
+		redacted := make([]string, len(values))
+		for i, v := range values {
+			redacted[i] = redactValue(v)
+		}
+		headers[name] = redacted
+	}
+	return headers
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cosmoparrot/internal/cache"
+	"cosmoparrot/internal/config"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleAnyRequest_RedactsEchoAndStore(t *testing.T) {
+	app := fiber.New()
+	app.Use(handleAnyRequest)
+
+	r := httptest.NewRequest(http.MethodPost, "/redacted", nil)
+	r.Header.Set("X-Request-Key", "redaction-key")
+	r.Header.Set("Authorization", "Bearer secret-token")
+	r.Header.Set("Cookie", "session=secret-session")
+	r.Header.Set("X-Visible", "visible")
+
+	resp, err := app.Test(r, -1)
+	assert.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "secret-token")
+	assert.NotContains(t, string(body), "secret-session")
+
+	var echoed request
+	assert.NoError(t, json.Unmarshal(body, &echoed))
+	assert.Equal(t, []string{redactedValue}, echoed.Headers["Authorization"])
+	assert.Equal(t, []string{"visible"}, echoed.Headers["X-Visible"])
+
+	stored, _ := cache.Current.Get("redaction-key")
+	assert.NotContains(t, stored.(string), "secret-token")
+	assert.NotContains(t, stored.(string), "secret-session")
+}
+
+func TestRedactHeaders_HashMode(t *testing.T) {
+	restoreConfig(t)
+	config.LoadedConfiguration.RedactionMode = "hash"
+	config.LoadedConfiguration.RedactionHashKey = "redaction-key"
+
+	headers := redactHeaders(map[string][]string{
+		"Authorization": {"Bearer abc"},
+		"X-Api-Key":     {"first", "second"},
+		"Content-Type":  {"application/json"},
+	})
+
+	// hmac-sha256("redaction-key", "Bearer abc")
+	assert.Equal(t, []string{"hmac-sha256:054c22a88b4990432b838c46ef1789ca38f937ae178668cb5caeb1364ed83c84"}, headers["Authorization"])
+	assert.Len(t, headers["X-Api-Key"], 2)
+	assert.NotEqual(t, headers["X-Api-Key"][0], headers["X-Api-Key"][1])
+	assert.Equal(t, []string{"application/json"}, headers["Content-Type"])
+
+	// equal values hash equally, so captures can be compared
+	again := redactHeaders(map[string][]string{"authorization": {"Bearer abc"}})
+	assert.Equal(t, headers["Authorization"], again["authorization"])
+}
+
+func TestRedactHeaders_HashModeProcessKey(t *testing.T) {
+	restoreConfig(t)
+	config.LoadedConfiguration.RedactionMode = "hash"
+	config.LoadedConfiguration.RedactionHashKey = ""
+
+	first := redactValue("secret")
+	assert.Equal(t, first, redactValue("secret"))
+	// the unkeyed digest of the value is not disclosed
+	assert.NotContains(t, first, "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b")
+}
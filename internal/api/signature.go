@@ -0,0 +1,98 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cosmoparrot/internal/config"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// signatureValidKey is the fiber local under which the signature verification
+// handler leaves its outcome for the echo handler.
+const signatureValidKey = "cosmoparrot.signatureValid"
+
+var signatureHashes = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+// newSignatureVerificationHandler checks the HMAC signature of echo requests
+// like a webhook consumer would. The outcome is left for the echo handler,
+// which answers invalid requests with the configured failure code.
+func newSignatureVerificationHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if isWebUIRequest(c) {
+			return c.Next()
+		}
+
+		valid := true
+		if err := verifySignature(c, time.Now()); err != nil {
+			log.Debug().Err(err).Msg("rejected request signature")
+			valid = false
+		}
+		c.Locals(signatureValidKey, valid)
+		return c.Next()
+	}
+}
+
+// verifySignature checks the signature header against the HMAC of the raw body.
+// With a timestamp header, the signed content is "<timestamp>.<body>" and the
+// timestamp (in Unix seconds) must be within the configured tolerance. The
+// signature may be hex or base64 encoded and prefixed with "<algorithm>=".
+func verifySignature(c *fiber.Ctx, now time.Time) error {
+	cfg := config.LoadedConfiguration
+	algorithm := strings.ToLower(cfg.SignatureAlgorithm)
+	newHash, ok := signatureHashes[algorithm]
+	if !ok {
+		return errors.New("unsupported signature algorithm")
+	}
+
+	header := strings.TrimSpace(c.Get(cfg.SignatureHeader))
+	if header == "" {
+		return errors.New("missing signature")
+	}
+	header = strings.TrimPrefix(header, algorithm+"=")
+	signature, err := hex.DecodeString(header)
+	if err != nil {
+		if signature, err = base64.StdEncoding.DecodeString(header); err != nil {
+			return errors.New("signature is neither hex nor base64 encoded")
+		}
+	}
+
+	mac := hmac.New(newHash, []byte(cfg.SignatureSecret))
+	if cfg.SignatureTimestampHeader != "" {
+		timestamp := strings.TrimSpace(c.Get(cfg.SignatureTimestampHeader))
+		seconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return errors.New("missing or malformed signature timestamp")
+		}
+		if age := now.Sub(time.Unix(seconds, 0)).Abs(); age > cfg.SignatureTimestampTolerance {
+			return errors.New("signature timestamp is outside the tolerance")
+		}
+		mac.Write([]byte(timestamp + "."))
+	}
+	// this buffers the body even if it is not mirrored
+	mac.Write(c.Body())
+
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
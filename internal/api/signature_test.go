@@ -0,0 +1,100 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bytes"
+	"cosmoparrot/internal/cache"
+	"cosmoparrot/internal/config"
+	"crypto/hmac"
+	"crypto/sha256"
+	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func hmacSHA256(secret string, content string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(content))
+	return mac.Sum(nil)
+}
+
+func TestSignatureVerification(t *testing.T) {
+	restoreConfig(t)
+	config.LoadedConfiguration.SignatureEnabled = true
+	config.LoadedConfiguration.SignatureAlgorithm = "sha256"
+	config.LoadedConfiguration.SignatureHeader = "X-Hub-Signature-256"
+	config.LoadedConfiguration.SignatureSecret = "webhook-secret"
+	config.LoadedConfiguration.SignatureTimestampHeader = "X-Timestamp"
+	config.LoadedConfiguration.SignatureTimestampTolerance = time.Minute
+	config.LoadedConfiguration.SignatureFailureResponseCode = http.StatusUnauthorized
+	app := NewApp(embed.FS{})
+
+	body := `{"event":"created"}`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		query     string
+		valid     bool
+	}{
+		{"hex with prefix", now, "sha256=" + hex.EncodeToString(hmacSHA256("webhook-secret", now+"."+body)), "", true},
+		{"base64", now, base64.StdEncoding.EncodeToString(hmacSHA256("webhook-secret", now+"."+body)), "", true},
+		{"unmirrored body", now, hex.EncodeToString(hmacSHA256("webhook-secret", now+"."+body)), "?mirrorBody=false", true},
+		{"wrong secret", now, hex.EncodeToString(hmacSHA256("other-secret", now+"."+body)), "", false},
+		{"stale timestamp", stale, hex.EncodeToString(hmacSHA256("webhook-secret", stale+"."+body)), "", false},
+		{"missing signature", now, "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/webhook"+tt.query, bytes.NewBufferString(body))
+			r.Header.Set("X-Request-Key", "signature-"+tt.name)
+			r.Header.Set("X-Timestamp", tt.timestamp)
+			if tt.signature != "" {
+				r.Header.Set("X-Hub-Signature-256", tt.signature)
+			}
+
+			expected := http.StatusOK
+			if !tt.valid {
+				expected = http.StatusUnauthorized
+			}
+			assert.Equal(t, expected, statusOf(t, app, r))
+
+			stored, found := cache.Current.Get("signature-" + tt.name)
+			require.True(t, found)
+			var requests []*request
+			require.NoError(t, json.Unmarshal([]byte(stored.(string)), &requests))
+			require.NotNil(t, requests[0].SignatureValid)
+			assert.Equal(t, tt.valid, *requests[0].SignatureValid)
+		})
+	}
+}
+
+func TestSignatureVerification_PrecedesBodyValidation(t *testing.T) {
+	restoreConfig(t)
+	config.LoadedConfiguration.SignatureEnabled = true
+	config.LoadedConfiguration.SignatureAlgorithm = "sha256"
+	config.LoadedConfiguration.SignatureHeader = "X-Signature"
+	config.LoadedConfiguration.SignatureSecret = "webhook-secret"
+	config.LoadedConfiguration.SignatureFailureResponseCode = http.StatusForbidden
+	app := NewApp(embed.FS{})
+
+	r := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString("{malformed"))
+	r.Header.Set("X-Signature", hex.EncodeToString(hmacSHA256("other-secret", "{malformed")))
+	assert.Equal(t, http.StatusForbidden, statusOf(t, app, r))
+}
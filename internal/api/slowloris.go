@@ -1,43 +1,173 @@
 // Copyright 2024 Deutsche Telekom IT GmbH
 //
 // SPDX-License-Identifier: Apache-2.0
+
 package api
 
 import (
 	"bufio"
+	"bytes"
 	"cosmoparrot/internal/config"
-	"github.com/gofiber/fiber/v2"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http/httputil"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/gofiber/fiber/v2"
 )
 
-func handleGetSlowloris(ctx *fiber.Ctx) error {
-	ctx.Set("Content-Type", "text/plain")
+const defaultSlowlorisContent = "."
 
-	durationSec := config.LoadedConfiguration.SlowlorisDefaultDurationSeconds
-	if d := ctx.Query("duration"); d != "" {
-		if parsed, err := time.ParseDuration(d + "s"); err == nil && parsed > 0 {
-			durationSec = int(parsed.Seconds())
-		}
+const maxSlowlorisChunkSize = 65536
+
+// minSlowlorisInterval keeps high byte rates from busy looping.
+const minSlowlorisInterval = time.Millisecond
+
+// maxSlowlorisDuration caps the durations of the query parameters like the
+// response delay, so a request cannot hold a connection indefinitely.
+const maxSlowlorisDuration = time.Duration(maxResponseDelayMs) * time.Millisecond
+
+// slowlorisStream is a slow response as requested by the query parameters of
+// /api/v1/slowloris.
+type slowlorisStream struct {
+	// duration is how long body chunks are written
+	duration time.Duration
+	interval time.Duration
+	// jitter is the maximum random deviation of each interval
+	jitter time.Duration
+	// ttfb is the delay before the first byte
+	ttfb        time.Duration
+	chunk       []byte
+	slowHeaders bool
+}
+
+func newSlowlorisStream(c *fiber.Ctx) *slowlorisStream {
+	s := &slowlorisStream{
+		duration: queryDuration(c, "duration", time.Duration(config.LoadedConfiguration.SlowlorisDefaultDurationSeconds)*time.Second),
+		interval: queryDuration(c, "interval", time.Duration(config.LoadedConfiguration.SlowlorisDefaultIntervalSeconds)*time.Second),
+		jitter:   queryDuration(c, "jitter", 0),
+		ttfb:     queryDuration(c, "ttfb", 0),
+	}
+
+	content := queryCaseInsensitive(c, "content")
+	if content == "" {
+		content = defaultSlowlorisContent
 	}
+	chunkSize := queryInt(c, "chunkSize", min(len(content), maxSlowlorisChunkSize), 1, maxSlowlorisChunkSize)
+	// the content is repeated or cut to the chunk size
+	s.chunk = bytes.Repeat([]byte(content), chunkSize/len(content)+1)[:chunkSize]
 
-	// add a query parameter to set the time between single dots
-	intervalSec := config.LoadedConfiguration.SlowlorisDefaultIntervalSeconds
-	if t := ctx.Query("interval"); t != "" {
-		if parsed, err := time.ParseDuration(t + "s"); err == nil && parsed > 0 {
-			intervalSec = int(parsed.Seconds())
+	if raw := queryCaseInsensitive(c, "bytesPerSecond"); raw != "" {
+		if rate, err := strconv.ParseFloat(strings.TrimSpace(raw), 64); err == nil && rate > 0 {
+			// tiny rates would exceed the cap or overflow
+			if interval := float64(chunkSize) / rate * float64(time.Second); interval <= float64(maxSlowlorisDuration) {
+				s.interval = time.Duration(interval)
+			}
 		}
 	}
+	s.interval = max(s.interval, minSlowlorisInterval)
 
+	// headers cannot be dribbled for requests received via net/http
+	if raw := queryCaseInsensitive(c, "slowHeaders"); raw != "" && !isBridged(c) {
+		s.slowHeaders, _ = strconv.ParseBool(strings.TrimSpace(raw))
+	}
+	return s
+}
+
+func handleGetSlowloris(ctx *fiber.Ctx) error {
+	stream := newSlowlorisStream(ctx)
+
+	if stream.slowHeaders {
+		// The headers are part of the slow response, so they are written to
+		// the hijacked connection along with the body.
+		ctx.Context().HijackSetNoResponse(true)
+		ctx.Context().Hijack(func(conn net.Conn) {
+			slowlorisActiveStreams.Inc()
+			defer slowlorisActiveStreams.Dec()
+
+			time.Sleep(stream.ttfb)
+			header := []byte("HTTP/1.1 200 OK\r\nContent-Type: text/plain\r\nTransfer-Encoding: chunked\r\nConnection: close\r\n\r\n")
+			for len(header) > 0 {
+				n := min(len(stream.chunk), len(header))
+				if _, err := conn.Write(header[:n]); err != nil {
+					return
+				}
+				header = header[n:]
+				time.Sleep(stream.nextInterval())
+			}
+
+			body := httputil.NewChunkedWriter(conn)
+			stream.write(time.Now(), func(chunk []byte) error {
+				_, err := body.Write(chunk)
+				return err
+			})
+			if body.Close() == nil {
+				_, _ = io.WriteString(conn, "\r\n")
+			}
+		})
+		return nil
+	}
+
+	ctx.Set("Content-Type", "text/plain")
 	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
-		start := time.Now()
-		for i := 0; ; i++ {
-			if time.Since(start) > time.Duration(durationSec)*time.Second {
-				break
+		slowlorisActiveStreams.Inc()
+		defer slowlorisActiveStreams.Dec()
+
+		time.Sleep(stream.ttfb)
+		stream.write(time.Now(), func(chunk []byte) error {
+			if _, err := w.Write(chunk); err != nil {
+				return err
 			}
-			w.WriteByte('.')
-			w.Flush()
-			time.Sleep(time.Duration(intervalSec) * time.Second)
-		}
+			return w.Flush()
+		})
 	})
 	return nil
 }
+
+// write writes a chunk after every interval until the duration has elapsed
+// since start.
+func (s *slowlorisStream) write(start time.Time, write func(chunk []byte) error) {
+	for time.Since(start) <= s.duration {
+		if err := write(s.chunk); err != nil {
+			// the client went away
+			return
+		}
+		time.Sleep(s.nextInterval())
+	}
+}
+
+// nextInterval returns the interval with a random deviation of up to jitter.
+func (s *slowlorisStream) nextInterval() time.Duration {
+	if s.jitter <= 0 {
+		return s.interval
+	}
+	deviation := time.Duration(rand.Int63n(int64(2*s.jitter)+1)) - s.jitter
+	return max(s.interval+deviation, 0)
+}
+
+// queryDuration reads a duration query parameter like "250ms", where plain
+// numbers are seconds. Falls back for missing, invalid or negative values and
+// for values above maxSlowlorisDuration.
+func queryDuration(c *fiber.Ctx, key string, fallback time.Duration) time.Duration {
+	raw := strings.TrimSpace(queryCaseInsensitive(c, key))
+	if raw == "" {
+		return fallback
+	}
+
+	if seconds, err := strconv.ParseFloat(raw, 64); err == nil {
+		// checked before the conversion, which would overflow
+		if seconds < 0 || math.IsNaN(seconds) || seconds > maxSlowlorisDuration.Seconds() {
+			return fallback
+		}
+		return time.Duration(seconds * float64(time.Second))
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 || d > maxSlowlorisDuration {
+		return fallback
+	}
+	return d
+}
@@ -6,10 +6,13 @@ package api
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestHandleGetSlowloris(t *testing.T) {
@@ -25,3 +28,95 @@ func TestHandleGetSlowloris(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 3, len(body))
 }
+
+func TestHandleGetSlowloris_Chunks(t *testing.T) {
+	app := fiber.New()
+	app.Get("/slowloris", handleGetSlowloris)
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"sub-second interval", "duration=250ms&interval=100ms", "..."},
+		{"plain seconds", "duration=0.25&interval=0.1", "..."},
+		{"chunk content", "duration=250ms&interval=100ms&content=ab&chunkSize=3", "abaabaaba"},
+		{"bytes per second", "duration=250ms&chunkSize=2&bytesPerSecond=20", "......"},
+		{"capped ttfb and jitter", "duration=250ms&interval=100ms&ttfb=1e300&jitter=61s", "..."},
+		{"capped bytes per second", "duration=250ms&interval=100ms&bytesPerSecond=1e-300", "..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", "/slowloris?"+tt.query, nil), 5000)
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(body))
+		})
+	}
+}
+
+func TestQueryDuration(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(queryDuration(c, "d", time.Second).String())
+	})
+
+	tests := map[string]string{
+		"250ms": "250ms",
+		"1.5":   "1.5s",
+		"60":    "1m0s",
+		"61":    "1s",
+		"2m":    "1s",
+		"1e300": "1s",
+		"-1":    "1s",
+		"NaN":   "1s",
+		"soon":  "1s",
+	}
+	for raw, want := range tests {
+		resp, err := app.Test(httptest.NewRequest("GET", "/?d="+raw, nil))
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, want, string(body), raw)
+	}
+}
+
+// startSlowlorisTestServer serves the app on a local listener, since timing
+// and slow headers are only observable on a real connection.
+func startSlowlorisTestServer(t *testing.T) string {
+	t.Helper()
+	app := fiber.New()
+	app.Get("/slowloris", handleGetSlowloris)
+	return "http://" + serveApp(t, app) + "/slowloris"
+}
+
+func TestHandleGetSlowloris_TimeToFirstByte(t *testing.T) {
+	url := startSlowlorisTestServer(t)
+
+	start := time.Now()
+	resp, err := http.Get(url + "?ttfb=200ms&duration=1ms&interval=1ms")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, ".", string(body))
+}
+
+func TestHandleGetSlowloris_SlowHeaders(t *testing.T) {
+	url := startSlowlorisTestServer(t)
+
+	// the headers are dribbled in chunks of 8 bytes every 10ms
+	start := time.Now()
+	resp, err := http.Get(url + "?slowHeaders=true&interval=10ms&chunkSize=8&duration=30ms")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.NotEmpty(t, body)
+	assert.Empty(t, strings.Trim(string(body), "."))
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultSSEInterval = time.Second
+const defaultSSECount = 10
+
+// sseFieldSanitizer keeps line breaks in field values from ending the field.
+var sseFieldSanitizer = strings.NewReplacer("\r", "", "\n", "")
+
+// sseStream is a stream of synthetic events as requested by the query
+// parameters of /api/v1/sse.
+type sseStream struct {
+	interval time.Duration
+	// count is the id of the last event, 0 streams endlessly
+	count int
+	// first is the id of the first event, following the Last-Event-ID
+	first          int
+	event          string
+	size           int
+	ids            bool
+	retry          int
+	dropAfter      int
+	malformedEvery int
+}
+
+func newSSEStream(c *fiber.Ctx) *sseStream {
+	s := &sseStream{
+		interval:       time.Duration(queryInt(c, "interval", int(defaultSSEInterval.Milliseconds()), 0, maxResponseDelayMs)) * time.Millisecond,
+		count:          queryInt(c, "count", defaultSSECount, 0, -1),
+		first:          1,
+		size:           queryInt(c, "size", 0, 0, maxResponseSize),
+		ids:            true,
+		retry:          queryInt(c, "retry", 0, 0, -1),
+		dropAfter:      queryInt(c, "dropAfter", 0, 0, -1),
+		malformedEvery: queryInt(c, "malformedEvery", 0, 0, -1),
+	}
+	// the stream outlives the request when the connection is hijacked
+	s.event = strings.Clone(sseFieldSanitizer.Replace(queryCaseInsensitive(c, "event")))
+	if raw := queryCaseInsensitive(c, "ids"); raw != "" {
+		if ids, err := strconv.ParseBool(strings.TrimSpace(raw)); err == nil {
+			s.ids = ids
+		}
+	}
+	if lastID, err := strconv.Atoi(strings.TrimSpace(c.Get("Last-Event-ID"))); err == nil && lastID >= 0 {
+		s.first = lastID + 1
+	}
+	return s
+}
+
+// handleGetSSE streams synthetic server-sent events. After a reconnect the
+// stream resumes after the Last-Event-ID; once all events were sent, 204 tells
+// the client to stop reconnecting.
+func handleGetSSE(c *fiber.Ctx) error {
+	stream := newSSEStream(c)
+
+	if key := extractStoreKey(c); key != "" {
+		if _, err := storeRequest(key, newRequestRecord(c)); err != nil {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	if stream.count > 0 && stream.first > stream.count {
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+
+	// Requests received via net/http are answered once the stream ended, and
+	// their connections cannot be hijacked.
+	if isBridged(c) {
+		if stream.count == 0 {
+			return c.Status(fiber.StatusBadRequest).SendString("endless streams are not supported over HTTP/2")
+		}
+		stream.dropAfter = 0
+	}
+
+	if stream.dropAfter > 0 {
+		// A stream writer cannot drop the connection, since fasthttp copies
+		// its output to the connection in the background. The response is
+		// written to the hijacked connection instead.
+		c.Context().HijackSetNoResponse(true)
+		c.Context().Hijack(func(conn net.Conn) {
+			header := "HTTP/1.1 200 OK\r\nContent-Type: text/event-stream\r\nCache-Control: no-cache\r\nX-Accel-Buffering: no\r\nTransfer-Encoding: chunked\r\n\r\n"
+			if _, err := io.WriteString(conn, header); err != nil {
+				return
+			}
+			stream.write(bufio.NewWriter(httputil.NewChunkedWriter(conn)), stream.dropAfter)
+			log.Debug().Msg("dropping the SSE connection")
+		})
+		return nil
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	// keep reverse proxies from buffering the stream
+	c.Set("X-Accel-Buffering", "no")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		stream.write(w, 0)
+	})
+	return nil
+}
+
+// write streams the events, at most limit of them unless limit is 0.
+func (s *sseStream) write(w *bufio.Writer, limit int) {
+	sseActiveStreams.Inc()
+	defer sseActiveStreams.Dec()
+
+	if s.retry > 0 {
+		fmt.Fprintf(w, "retry: %d\n\n", s.retry)
+	}
+	for id := s.first; s.count == 0 || id <= s.count; id++ {
+		if id > s.first {
+			time.Sleep(s.interval)
+		}
+		s.writeEvent(w, id)
+		if err := w.Flush(); err != nil {
+			// the client went away
+			return
+		}
+		if limit > 0 && id-s.first+1 >= limit {
+			return
+		}
+	}
+}
+
+// writeEvent writes the event with the given id. Malformed events carry a
+// truncated JSON payload and a non-numeric retry field, and lack the blank
+// line that ends an event, so clients merge them into the next one.
+func (s *sseStream) writeEvent(w *bufio.Writer, id int) {
+	if s.ids {
+		fmt.Fprintf(w, "id: %d\n", id)
+	}
+	if s.event != "" {
+		fmt.Fprintf(w, "event: %s\n", s.event)
+	}
+
+	data := fmt.Sprintf(`{"sequence":%d`, id)
+	if s.size > 0 {
+		offset := int(paddingOffset.Add(1) % maxResponseSizePaddingWindowSize)
+		data += fmt.Sprintf(`,"padding":"%s"`, paddingSource[offset:offset+s.size])
+	}
+	if s.malformedEvery > 0 && id%s.malformedEvery == 0 {
+		fmt.Fprintf(w, "data: %s\nretry: soon\n", data)
+		return
+	}
+	fmt.Fprintf(w, "data: %s}\n\n", data)
+}
+
+// queryInt reads an integer query parameter. Returns fallback for missing,
+// non-integer or out-of-range values; a negative maximum means unbounded.
+func queryInt(c *fiber.Ctx, key string, fallback, minimum, maximum int) int {
+	raw := queryCaseInsensitive(c, key)
+	if raw == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || n < minimum || (maximum >= 0 && n > maximum) {
+		return fallback
+	}
+	return n
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cosmoparrot/internal/cache"
+	"embed"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startSSETestServer serves the app on a local listener, since the SSE stream
+// writes to the connection directly.
+func startSSETestServer(t *testing.T) string {
+	t.Helper()
+	app := NewApp(embed.FS{})
+	return "http://" + serveApp(t, app) + "/api/v1/sse"
+}
+
+func getSSE(t *testing.T, url string, header http.Header) (*http.Response, string, error) {
+	t.Helper()
+	r, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if header != nil {
+		r.Header = header
+	}
+	resp, err := http.DefaultClient.Do(r)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return resp, string(body), err
+}
+
+func TestSSE_Events(t *testing.T) {
+	url := startSSETestServer(t)
+
+	resp, body, err := getSSE(t, url+"?count=2&interval=0&event=tick&retry=2000", nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "retry: 2000\n\n"+
+		"id: 1\nevent: tick\ndata: {\"sequence\":1}\n\n"+
+		"id: 2\nevent: tick\ndata: {\"sequence\":2}\n\n", body)
+
+	_, body, err = getSSE(t, url+"?count=1&ids=false&size=16", nil)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(body, "data: "), body)
+	var data struct {
+		Sequence int    `json:"sequence"`
+		Padding  string `json:"padding"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(body, "data: "))), &data))
+	assert.Equal(t, 1, data.Sequence)
+	assert.Len(t, data.Padding, 16)
+}
+
+func TestSSE_LastEventID(t *testing.T) {
+	url := startSSETestServer(t) + "?count=3&interval=0"
+	cache.Current.Delete("sse-key")
+
+	header := http.Header{"Last-Event-Id": {"2"}, "X-Request-Key": {"sse-key"}}
+	_, body, err := getSSE(t, url, header)
+	require.NoError(t, err)
+	assert.Equal(t, "id: 3\ndata: {\"sequence\":3}\n\n", body)
+
+	// the stream is complete, so the client should stop reconnecting
+	header.Set("Last-Event-ID", "3")
+	resp, _, err := getSSE(t, url, header)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	stored, found := cache.Current.Get("sse-key")
+	require.True(t, found)
+	var requests []*request
+	require.NoError(t, json.Unmarshal([]byte(stored.(string)), &requests))
+	require.Len(t, requests, 2)
+	assert.Equal(t, []string{"3"}, requests[1].Headers["Last-Event-Id"])
+}
+
+func TestSSE_Misbehaviour(t *testing.T) {
+	url := startSSETestServer(t)
+
+	_, body, err := getSSE(t, url+"?count=5&interval=0&dropAfter=2", nil)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, "id: 1\ndata: {\"sequence\":1}\n\nid: 2\ndata: {\"sequence\":2}\n\n", body)
+
+	_, body, err = getSSE(t, url+"?count=3&interval=0&malformedEvery=2", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "id: 1\ndata: {\"sequence\":1}\n\n"+
+		"id: 2\ndata: {\"sequence\":2\nretry: soon\n"+
+		"id: 3\ndata: {\"sequence\":3}\n\n", body)
+}
+
+func TestSSE_HTTP2(t *testing.T) {
+	restoreConfig(t)
+	app, _ := newApp(embed.FS{})
+	addr := serveHTTP2(t, newHTTP2Server(app, nil), false)
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+
+	resp, err := client.Get("http://" + addr + "/api/v1/sse?count=0")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// the connection cannot be dropped, so the stream is complete
+	resp, err = client.Get("http://" + addr + "/api/v1/sse?count=2&interval=0&dropAfter=1")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "id: 1\ndata: {\"sequence\":1}\n\nid: 2\ndata: {\"sequence\":2}\n\n", string(body))
+}
@@ -8,8 +8,11 @@ import (
 	"cosmoparrot/internal/cache"
 	"encoding/json"
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/log"
+	go_cache "github.com/patrickmn/go-cache"
+	"github.com/rs/zerolog/log"
+	"slices"
 	"sort"
+	"sync"
 )
 
 func handleGetAllRequests(c *fiber.Ctx) error {
@@ -19,7 +22,7 @@ func handleGetAllRequests(c *fiber.Ctx) error {
 
 		err := json.Unmarshal([]byte(v.Object.(string)), &requests)
 		if err != nil {
-			log.Errorf("failed to deserialize data, error: %s", err.Error())
+			log.Error().Err(err).Msg("failed to deserialize data")
 			return c.SendStatus(fiber.StatusInternalServerError)
 		}
 
@@ -38,14 +41,14 @@ func handleGetAllRequests(c *fiber.Ctx) error {
 }
 func handleGetRequestByKey(c *fiber.Ctx) error {
 	if key := c.Params("key"); key != "" {
-		log.Debugf("reading from cache with key %s", key)
+		log.Debug().Str("key", key).Msg("reading from cache")
 
 		if entry, found := cache.Current.Get(key); found {
 			var requests []*request
 
 			err := json.Unmarshal([]byte(entry.(string)), &requests)
 			if err != nil {
-				log.Errorf("failed to deserialize data, error: %s", err.Error())
+				log.Error().Err(err).Msg("failed to deserialize data")
 				return c.SendStatus(fiber.StatusInternalServerError)
 			}
 
@@ -59,3 +62,96 @@ func handleGetRequestByKey(c *fiber.Ctx) error {
 
 	return c.SendStatus(fiber.StatusNotFound)
 }
+
+// storeMutex serializes writes to the store, since appending a request reads
+// and rewrites the whole entry.
+var storeMutex sync.Mutex
+
+// storeRequest appends a request to the requests stored under key and returns
+// how many are stored under it.
+func storeRequest(key string, reqData *request) (int, error) {
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+
+	requests := []*request{}
+	if entry, found := cache.Current.Get(key); found {
+		if err := json.Unmarshal([]byte(entry.(string)), &requests); err != nil {
+			log.Error().Err(err).Msg("failed to deserialize data")
+			return 0, err
+		}
+	}
+	requests = append(requests, reqData)
+
+	jsonData, err := json.Marshal(requests)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to serialize data")
+		return 0, err
+	}
+	cache.Current.Set(key, string(jsonData), go_cache.DefaultExpiration)
+	if reqData.TraceID != "" {
+		indexTrace(reqData.TraceID, key)
+	}
+	return len(requests), nil
+}
+
+// traceIndexMutex serializes writes to the trace index, since adding a key reads
+// and rewrites the keys of the trace.
+var traceIndexMutex sync.Mutex
+
+// indexTrace remembers that requests of the given trace are stored under key.
+func indexTrace(traceID, key string) {
+	traceIndexMutex.Lock()
+	defer traceIndexMutex.Unlock()
+
+	var keys []string
+	if entry, found := cache.TraceIndex.Get(traceID); found {
+		keys = entry.([]string)
+	}
+	if slices.Contains(keys, key) {
+		return
+	}
+	cache.TraceIndex.Set(traceID, append(slices.Clone(keys), key), go_cache.DefaultExpiration)
+}
+
+func handleGetRequestsByTraceId(c *fiber.Ctx) error {
+	traceID := normalizeTraceID(c.Params("traceId"))
+	if traceID == "" {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+
+	entry, found := cache.TraceIndex.Get(traceID)
+	if !found {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+
+	var list []*request
+	for _, key := range entry.([]string) {
+		stored, found := cache.Current.Get(key)
+		if !found {
+			continue
+		}
+
+		var requests []*request
+		err := json.Unmarshal([]byte(stored.(string)), &requests)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to deserialize data")
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+
+		for _, r := range requests {
+			if r.TraceID == traceID {
+				list = append(list, r)
+			}
+		}
+	}
+
+	if list == nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Time.After(list[j].Time)
+	})
+
+	return c.Status(fiber.StatusOK).JSON(list)
+}
@@ -7,12 +7,14 @@ package api
 import (
 	"cosmoparrot/internal/cache"
 	"encoding/json"
+	"fmt"
 	"github.com/gofiber/fiber/v2"
 	c "github.com/patrickmn/go-cache"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 )
@@ -21,6 +23,7 @@ func setupTestApp() *fiber.App {
 	app := fiber.New()
 	app.Get("/api/v1/requests", handleGetAllRequests)
 	app.Get("/api/v1/requests/:key", handleGetRequestByKey)
+	app.Get("/api/v1/traces/:traceId/requests", handleGetRequestsByTraceId)
 	return app
 }
 
@@ -81,3 +84,87 @@ func TestHandleGetRequestByKey_NotFound(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 }
+
+func TestHandleGetRequestsByTraceId(t *testing.T) {
+	cache.Current = c.New(5*time.Minute, 10*time.Minute)
+	cache.TraceIndex = c.New(5*time.Minute, 10*time.Minute)
+
+	app := setupTestApp()
+	app.Use(handleAnyRequest)
+
+	// W3C trace context
+	r := httptest.NewRequest(http.MethodPost, "/callback", nil)
+	r.Header.Set("X-Request-Key", "shared-key")
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, err := app.Test(r, -1)
+	assert.NoError(t, err)
+
+	// B3 with a 64-bit trace id, stored under the same key
+	r = httptest.NewRequest(http.MethodPost, "/callback", nil)
+	r.Header.Set("X-Request-Key", "shared-key")
+	r.Header.Set("X-B3-TraceId", "a3ce929d0e0e4736")
+	r.Header.Set("X-B3-SpanId", "00f067aa0ba902b8")
+	r.Header.Set("X-B3-Sampled", "1")
+	_, err = app.Test(r, -1)
+	assert.NoError(t, err)
+
+	r = httptest.NewRequest(http.MethodGet, "/api/v1/traces/4bf92f3577b34da6a3ce929d0e0e4736/requests", nil)
+	resp, err := app.Test(r, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var requests []*request
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&requests))
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "00f067aa0ba902b7", requests[0].SpanID)
+
+	// 64-bit ids can be looked up as sent
+	r = httptest.NewRequest(http.MethodGet, "/api/v1/traces/A3CE929D0E0E4736/requests", nil)
+	resp, err = app.Test(r, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	requests = nil
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&requests))
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "0000000000000000a3ce929d0e0e4736", requests[0].TraceID)
+
+	// trace ids are not used as store keys
+	_, found := cache.Current.Get("4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.False(t, found)
+	assert.Equal(t, 1, cache.Current.ItemCount())
+}
+
+func TestHandleGetRequestsByTraceId_NotFound(t *testing.T) {
+	cache.TraceIndex = c.New(5*time.Minute, 10*time.Minute)
+
+	app := setupTestApp()
+	for _, id := range []string{"4bf92f3577b34da6a3ce929d0e0e4736", "not-a-trace-id"} {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/traces/"+id+"/requests", nil)
+		resp, err := app.Test(r, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestIndexTrace_Concurrent(t *testing.T) {
+	cache.TraceIndex = c.New(5*time.Minute, 10*time.Minute)
+
+	// the goroutines are released at once to make lost updates likely
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := range 500 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			indexTrace("4bf92f3577b34da6a3ce929d0e0e4736", fmt.Sprintf("key-%d", i))
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	keys, found := cache.TraceIndex.Get("4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.True(t, found)
+	assert.Len(t, keys, 500)
+}
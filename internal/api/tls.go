@@ -0,0 +1,212 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cosmoparrot/internal/certs"
+	"cosmoparrot/internal/config"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// serverTLS is the TLS setup of the HTTPS listener and the misbehaving TLS
+// listeners.
+type serverTLS struct {
+	config *tls.Config
+	// ca is only set if the certificate was generated
+	ca        *certs.Authority
+	scenarios []tlsScenario
+}
+
+// tlsScenario is a listener whose TLS setup is broken on purpose, so that the
+// handling of TLS failures by clients can be tested.
+type tlsScenario struct {
+	name   string
+	port   int
+	config *tls.Config
+}
+
+// tlsScenariosEnabled reports whether any misbehaving TLS listener is configured.
+func tlsScenariosEnabled() bool {
+	cfg := config.LoadedConfiguration
+	return cfg.TLSExpiredPort != 0 || cfg.TLSWrongHostnamePort != 0 || cfg.TLSSelfSignedPort != 0 || cfg.TLSLegacyPort != 0
+}
+
+// newServerTLS loads the configured certificate or, if none is configured,
+// generates a CA and a certificate for the configured host names. The CA is
+// regenerated on every start.
+func newServerTLS() (*serverTLS, error) {
+	cfg := config.LoadedConfiguration
+	setup := &serverTLS{}
+	var cert tls.Certificate
+	var err error
+	if cfg.TLSCertFile != "" {
+		if cert, err = tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile); err != nil {
+			return nil, err
+		}
+	} else {
+		if setup.ca, err = certs.NewAuthority("Cosmoparrot CA"); err != nil {
+			return nil, err
+		}
+		if cert, err = setup.ca.Issue(certs.LeafOptions{Hosts: cfg.TLSHostnames}); err != nil {
+			return nil, err
+		}
+		log.Info().Strs("hosts", cfg.TLSHostnames).Msg("generated TLS certificate")
+	}
+
+	setup.config = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if err := setup.configureClientAuth(); err != nil {
+		return nil, err
+	}
+	if err := setup.configureScenarios(); err != nil {
+		return nil, err
+	}
+	return setup, nil
+}
+
+// configureClientAuth requests or requires client certificates issued by the
+// configured CAs.
+func (s *serverTLS) configureClientAuth() error {
+	cfg := config.LoadedConfiguration
+	switch strings.ToLower(cfg.TLSClientAuth) {
+	case "request":
+		s.config.ClientAuth = tls.VerifyClientCertIfGiven
+	case "require":
+		s.config.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil
+	}
+
+	bundle, err := os.ReadFile(cfg.TLSClientCAFile)
+	if err != nil {
+		return fmt.Errorf("failed to read client CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bundle) {
+		return errors.New("client CA bundle does not contain any certificates")
+	}
+	s.config.ClientCAs = pool
+	return nil
+}
+
+// configureScenarios generates the certificates of the misbehaving listeners.
+// Apart from the self-signed one, they are issued by the generated CA, so that
+// clients trusting it only fail on the intended check.
+func (s *serverTLS) configureScenarios() error {
+	if !tlsScenariosEnabled() {
+		return nil
+	}
+	cfg := config.LoadedConfiguration
+	ca := s.ca
+	if ca == nil {
+		// with tlsCertFile there is no generated CA to issue from
+		var err error
+		if ca, err = certs.NewAuthority("Cosmoparrot Scenario CA"); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	specs := []struct {
+		name    string
+		port    int
+		options certs.LeafOptions
+		legacy  bool
+	}{
+		{"expired", cfg.TLSExpiredPort, certs.LeafOptions{Hosts: cfg.TLSHostnames, NotBefore: now.AddDate(0, 0, -30), NotAfter: now.AddDate(0, 0, -1)}, false},
+		{"wrong hostname", cfg.TLSWrongHostnamePort, certs.LeafOptions{Hosts: []string{"wrong.hostname.invalid"}}, false},
+		{"self-signed", cfg.TLSSelfSignedPort, certs.LeafOptions{Hosts: cfg.TLSHostnames}, false},
+		{"legacy", cfg.TLSLegacyPort, certs.LeafOptions{Hosts: cfg.TLSHostnames}, true},
+	}
+	for _, spec := range specs {
+		if spec.port == 0 {
+			continue
+		}
+		var cert tls.Certificate
+		var err error
+		if spec.name == "self-signed" {
+			cert, err = certs.SelfSigned(spec.options)
+		} else {
+			cert, err = ca.Issue(spec.options)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to generate the %s certificate: %w", spec.name, err)
+		}
+
+		tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+		if spec.legacy {
+			// only protocol versions and ciphers that current clients refuse
+			tlsConfig.MinVersion = tls.VersionTLS10
+			tlsConfig.MaxVersion = tls.VersionTLS11
+			tlsConfig.CipherSuites = []uint16{
+				tls.TLS_ECDHE_ECDSA_WITH_RC4_128_SHA,
+				tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
+				tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,
+			}
+		}
+		s.scenarios = append(s.scenarios, tlsScenario{name: spec.name, port: spec.port, config: tlsConfig})
+	}
+	return nil
+}
+
+// handleGetCA serves the generated CA certificate, so clients can trust the
+// HTTPS listener.
+func (s *serverTLS) handleGetCA(c *fiber.Ctx) error {
+	if s.ca == nil {
+		return c.Status(fiber.StatusNotFound).SendString("the TLS certificate was not generated")
+	}
+	c.Set(fiber.HeaderContentType, "application/x-pem-file")
+	return c.Send(s.ca.PEM())
+}
+
+// clientCertificate describes the certificate a client authenticated with.
+type clientCertificate struct {
+	Subject     string   `json:"subject"`
+	Issuer      string   `json:"issuer"`
+	SANs        []string `json:"sans,omitempty"`
+	Fingerprint string   `json:"fingerprint"`
+}
+
+// peerCertificate returns the verified client certificate of a request, if any.
+func peerCertificate(c *fiber.Ctx) *clientCertificate {
+	state := c.Context().TLSConnectionState()
+	if bridged, ok := c.Locals(tlsStateKey).(*tls.ConnectionState); ok {
+		state = bridged
+	}
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+
+	cert := state.PeerCertificates[0]
+	sans := slices.Clone(cert.DNSNames)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	sum := sha256.Sum256(cert.Raw)
+	return &clientCertificate{
+		Subject:     cert.Subject.String(),
+		Issuer:      cert.Issuer.String(),
+		SANs:        sans,
+		Fingerprint: "sha256:" + hex.EncodeToString(sum[:]),
+	}
+}
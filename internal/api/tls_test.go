@@ -0,0 +1,214 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cosmoparrot/internal/cache"
+	"cosmoparrot/internal/certs"
+	"cosmoparrot/internal/config"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLS_GeneratedCertificate(t *testing.T) {
+	restoreConfig(t)
+	config.LoadedConfiguration.TLSEnabled = true
+	config.LoadedConfiguration.TLSHostnames = []string{"localhost"}
+	config.LoadedConfiguration.MethodResponseCodeMap = map[string]int{}
+	app, tlsSetup := newApp(embed.FS{})
+	require.NotNil(t, tlsSetup)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/tls/ca.pem", nil), -1)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	caPEM, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(caPEM))
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", tlsSetup.config)
+	require.NoError(t, err)
+	go func() { _ = app.Listener(ln) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	port := ln.Addr().(*net.TCPAddr).Port
+	resp, err = client.Get(fmt.Sprintf("https://localhost:%d/echo", port))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotNil(t, resp.TLS)
+}
+
+func TestTLS_CANotServedForConfiguredCertificate(t *testing.T) {
+	app := fiber.New()
+	app.Get("/ca.pem", (&serverTLS{}).handleGetCA)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/ca.pem", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+// startTLSTestServer serves the app on a local TLS listener and returns a client
+// that trusts the generated CA.
+func startTLSTestServer(t *testing.T) (*serverTLS, string, *x509.CertPool) {
+	t.Helper()
+	app, tlsSetup := newApp(embed.FS{})
+	require.NotNil(t, tlsSetup)
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", tlsSetup.config)
+	require.NoError(t, err)
+	go func() { _ = app.Listener(ln) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+
+	pool := x509.NewCertPool()
+	pool.AddCert(tlsSetup.ca.Cert)
+	return tlsSetup, fmt.Sprintf("https://localhost:%d", ln.Addr().(*net.TCPAddr).Port), pool
+}
+
+// writeClientCA generates a CA for client certificates and writes it as bundle.
+func writeClientCA(t *testing.T) (*certs.Authority, string) {
+	t.Helper()
+	ca, err := certs.NewAuthority("Client CA")
+	require.NoError(t, err)
+	bundle := filepath.Join(t.TempDir(), "clients.pem")
+	require.NoError(t, os.WriteFile(bundle, ca.PEM(), 0o600))
+	return ca, bundle
+}
+
+func TestTLS_ClientCertificates(t *testing.T) {
+	clientCA, bundle := writeClientCA(t)
+	restoreConfig(t)
+	config.LoadedConfiguration.TLSEnabled = true
+	config.LoadedConfiguration.TLSHostnames = []string{"localhost"}
+	config.LoadedConfiguration.TLSClientAuth = "require"
+	config.LoadedConfiguration.TLSClientCAFile = bundle
+	_, baseURL, pool := startTLSTestServer(t)
+
+	// without a client certificate the handshake fails
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	_, err := client.Get(baseURL + "/echo")
+	assert.Error(t, err)
+
+	clientCert, err := clientCA.Issue(certs.LeafOptions{CommonName: "horizon", Hosts: []string{"horizon.example"}})
+	require.NoError(t, err)
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{clientCert},
+	}}}
+	r, err := http.NewRequest(http.MethodPost, baseURL+"/echo", nil)
+	require.NoError(t, err)
+	r.Header.Set("X-Request-Key", "mtls-key")
+	resp, err := client.Do(r)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	stored, found := cache.Current.Get("mtls-key")
+	require.True(t, found)
+	var requests []*request
+	require.NoError(t, json.Unmarshal([]byte(stored.(string)), &requests))
+	recorded := requests[len(requests)-1].ClientCertificate
+	require.NotNil(t, recorded)
+	assert.Equal(t, "CN=horizon,O=Cosmoparrot", recorded.Subject)
+	assert.Equal(t, "CN=Client CA,O=Cosmoparrot", recorded.Issuer)
+	assert.Equal(t, []string{"horizon.example"}, recorded.SANs)
+	sum := sha256.Sum256(clientCert.Leaf.Raw)
+	assert.Equal(t, "sha256:"+hex.EncodeToString(sum[:]), recorded.Fingerprint)
+}
+
+func TestTLS_RequestedClientCertificates(t *testing.T) {
+	_, bundle := writeClientCA(t)
+	restoreConfig(t)
+	config.LoadedConfiguration.TLSEnabled = true
+	config.LoadedConfiguration.TLSHostnames = []string{"localhost"}
+	config.LoadedConfiguration.TLSClientAuth = "request"
+	config.LoadedConfiguration.TLSClientCAFile = bundle
+	tlsSetup, baseURL, pool := startTLSTestServer(t)
+
+	// clients without a certificate are still served
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	resp, err := client.Get(baseURL + "/echo")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// certificates of other CAs are rejected
+	untrusted, err := tlsSetup.ca.Issue(certs.LeafOptions{CommonName: "horizon"})
+	require.NoError(t, err)
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs: pool,
+		// send the certificate even though the server does not accept its issuer
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &untrusted, nil
+		},
+	}}}
+	_, err = client.Get(baseURL + "/echo")
+	assert.Error(t, err)
+}
+
+func TestTLS_Scenarios(t *testing.T) {
+	restoreConfig(t)
+	config.LoadedConfiguration.TLSHostnames = []string{"localhost"}
+	config.LoadedConfiguration.TLSExpiredPort = 1
+	config.LoadedConfiguration.TLSWrongHostnamePort = 2
+	config.LoadedConfiguration.TLSSelfSignedPort = 3
+	config.LoadedConfiguration.TLSLegacyPort = 4
+	app, tlsSetup := newApp(embed.FS{})
+	require.NotNil(t, tlsSetup)
+	require.Len(t, tlsSetup.scenarios, 4)
+	t.Cleanup(func() { _ = app.Shutdown() })
+
+	pool := x509.NewCertPool()
+	pool.AddCert(tlsSetup.ca.Cert)
+	urls := make(map[string]string)
+	for _, scenario := range tlsSetup.scenarios {
+		// the configured ports are replaced by random ones
+		ln, err := tls.Listen("tcp", "127.0.0.1:0", scenario.config)
+		require.NoError(t, err)
+		go func() { _ = app.Listener(ln) }()
+		urls[scenario.name] = fmt.Sprintf("https://localhost:%d/echo", ln.Addr().(*net.TCPAddr).Port)
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+
+	_, err := client.Get(urls["expired"])
+	var invalid x509.CertificateInvalidError
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, x509.Expired, invalid.Reason)
+
+	_, err = client.Get(urls["wrong hostname"])
+	var hostname x509.HostnameError
+	assert.ErrorAs(t, err, &hostname)
+
+	_, err = client.Get(urls["self-signed"])
+	var unknown x509.UnknownAuthorityError
+	assert.ErrorAs(t, err, &unknown)
+
+	_, err = client.Get(urls["legacy"])
+	assert.ErrorContains(t, err, "protocol version")
+
+	legacyClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      pool,
+		MinVersion:   tls.VersionTLS10,
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA},
+	}}}
+	resp, err := legacyClient.Get(urls["legacy"])
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, uint16(tls.VersionTLS11), resp.TLS.Version)
+}
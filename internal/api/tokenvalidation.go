@@ -0,0 +1,114 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cosmoparrot/internal/auth"
+	"cosmoparrot/internal/config"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// tokenValidationKey is the fiber local under which the token validation
+// handler leaves its outcome for the echo handler.
+const tokenValidationKey = "cosmoparrot.tokenValidation"
+
+// tokenValidation is the outcome of validating the bearer token of an echo
+// request, as recorded in the request store.
+type tokenValidation struct {
+	Valid   bool   `json:"valid"`
+	Error   string `json:"error,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	status  int
+}
+
+// newTokenValidationHandler emulates a consumer endpoint protected by OAuth2.
+// Requests without a valid bearer token are answered with 401, tokens lacking
+// one of the required scopes with 403. Either way the echo handler still
+// records the request along with the outcome.
+func newTokenValidationHandler() fiber.Handler {
+	cfg := config.LoadedConfiguration
+	keys, err := auth.LoadKeySet(cfg.EchoJwtJwksFile)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to load JWKS, rejecting all echo requests")
+	}
+	verifier := &auth.Verifier{Keys: keys, Issuer: cfg.EchoJwtIssuer, Audience: cfg.EchoJwtAudience}
+
+	return func(c *fiber.Ctx) error {
+		if isWebUIRequest(c) {
+			return c.Next()
+		}
+
+		validation := validateToken(c, verifier, cfg.EchoJwtRequiredScopes)
+		if !validation.Valid {
+			log.Debug().Str("error", validation.Error).Msg("rejected echo token")
+		}
+		c.Locals(tokenValidationKey, validation)
+		return c.Next()
+	}
+}
+
+func validateToken(c *fiber.Ctx, verifier *auth.Verifier, requiredScopes []string) *tokenValidation {
+	token, ok := bearerToken(c)
+	if !ok {
+		c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="`+authRealm+`"`)
+		return &tokenValidation{Error: "missing bearer token", status: fiber.StatusUnauthorized}
+	}
+	if verifier.Keys == nil {
+		return invalidToken(c, "no signing keys configured")
+	}
+
+	claims, err := verifier.Verify(token)
+	if err != nil {
+		return invalidToken(c, err.Error())
+	}
+
+	subject, _ := claims.GetSubject()
+	granted := tokenScopes(claims)
+	for _, scope := range requiredScopes {
+		if !slices.Contains(granted, scope) {
+			c.Set(fiber.HeaderWWWAuthenticate, fmt.Sprintf(`Bearer realm=%q, error="insufficient_scope", scope=%q`,
+				authRealm, strings.Join(requiredScopes, " ")))
+			return &tokenValidation{
+				Error:   fmt.Sprintf("missing scope %q", scope),
+				Subject: subject,
+				status:  fiber.StatusForbidden,
+			}
+		}
+	}
+
+	return &tokenValidation{Valid: true, Subject: subject}
+}
+
+func invalidToken(c *fiber.Ctx, description string) *tokenValidation {
+	// the description is a quoted-string, see RFC 6750, section 3
+	c.Set(fiber.HeaderWWWAuthenticate, fmt.Sprintf(`Bearer realm=%q, error="invalid_token", error_description=%q`,
+		authRealm, strings.ReplaceAll(description, `"`, `'`)))
+	return &tokenValidation{Error: description, status: fiber.StatusUnauthorized}
+}
+
+// tokenScopes returns the scopes granted by the "scope" claim (RFC 8693), or
+// by the "scp" claim some identity providers use instead.
+func tokenScopes(claims jwt.MapClaims) []string {
+	var scopes []string
+	for _, name := range []string{"scope", "scp"} {
+		switch v := claims[name].(type) {
+		case string:
+			scopes = append(scopes, strings.Fields(v)...)
+		case []any:
+			for _, s := range v {
+				if s, ok := s.(string); ok {
+					scopes = append(scopes, s)
+				}
+			}
+		}
+	}
+	return scopes
+}
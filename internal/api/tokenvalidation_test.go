@@ -0,0 +1,100 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cosmoparrot/internal/cache"
+	"cosmoparrot/internal/config"
+	"embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenValidation(t *testing.T) {
+	key, jwksFile := writeTestJWKS(t)
+	restoreConfig(t)
+	config.LoadedConfiguration.EchoJwtEnabled = true
+	config.LoadedConfiguration.EchoJwtJwksFile = jwksFile
+	config.LoadedConfiguration.EchoJwtIssuer = "https://idp.example"
+	config.LoadedConfiguration.EchoJwtAudience = "consumer"
+	config.LoadedConfiguration.EchoJwtRequiredScopes = []string{"events:write"}
+	app := NewApp(embed.FS{})
+
+	valid := jwt.MapClaims{
+		"iss":   "https://idp.example",
+		"aud":   "consumer",
+		"sub":   "horizon",
+		"scope": "events:read events:write",
+		"exp":   time.Now().Add(time.Minute).Unix(),
+	}
+	expired := jwt.MapClaims{"iss": "https://idp.example", "aud": "consumer", "exp": time.Now().Add(-time.Minute).Unix()}
+	unscoped := jwt.MapClaims{"iss": "https://idp.example", "aud": "consumer", "sub": "horizon", "scp": []string{"events:read"}, "exp": time.Now().Add(time.Minute).Unix()}
+
+	tests := []struct {
+		name       string
+		token      string
+		status     int
+		challenge  string
+		validation tokenValidation
+	}{
+		{"missing token", "", http.StatusUnauthorized, `Bearer realm="cosmoparrot"`,
+			tokenValidation{Error: "missing bearer token"}},
+		{"expired token", signTestJWT(t, key, expired), http.StatusUnauthorized, `error="invalid_token"`,
+			tokenValidation{Error: "token has invalid claims: token is expired"}},
+		{"missing scope", signTestJWT(t, key, unscoped), http.StatusForbidden, `error="insufficient_scope", scope="events:write"`,
+			tokenValidation{Error: `missing scope "events:write"`, Subject: "horizon"}},
+		{"valid token", signTestJWT(t, key, valid), http.StatusOK, "",
+			tokenValidation{Valid: true, Subject: "horizon"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/callback", nil)
+			r.Header.Set("X-Request-Key", "token-"+tt.name)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			resp, err := app.Test(r, -1)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+			if tt.challenge == "" {
+				assert.Empty(t, resp.Header.Get("WWW-Authenticate"))
+			} else {
+				assert.Contains(t, resp.Header.Get("WWW-Authenticate"), tt.challenge)
+			}
+
+			stored, found := cache.Current.Get("token-" + tt.name)
+			require.True(t, found)
+			var requests []*request
+			require.NoError(t, json.Unmarshal([]byte(stored.(string)), &requests))
+			require.NotNil(t, requests[0].TokenValidation)
+			assert.Equal(t, tt.validation, *requests[0].TokenValidation)
+		})
+	}
+}
+
+func TestTokenValidation_PrecedesBodyValidation(t *testing.T) {
+	_, jwksFile := writeTestJWKS(t)
+	restoreConfig(t)
+	config.LoadedConfiguration.EchoJwtEnabled = true
+	config.LoadedConfiguration.EchoJwtJwksFile = jwksFile
+	app := NewApp(embed.FS{})
+
+	r := httptest.NewRequest(http.MethodPost, "/callback", strings.NewReader("{malformed"))
+	r.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(r, -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("WWW-Authenticate"), `Bearer realm="cosmoparrot"`)
+}
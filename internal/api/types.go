@@ -7,13 +7,54 @@ package api
 import (
 	"encoding/json"
 	"time"
+
+	"github.com/gofiber/fiber/v2"
 )
 
 type request struct {
 	Time    time.Time           `json:"time"`
 	Path    string              `json:"path"`
 	Method  string              `json:"method"`
+	Proto   string              `json:"protocol,omitempty"`
+	TraceID string              `json:"traceId,omitempty"`
+	SpanID  string              `json:"spanId,omitempty"`
 	Headers map[string][]string `json:"headers,omitempty"`
 	Body    json.RawMessage     `json:"body,omitempty"`
 	Padding string              `json:"padding,omitempty"`
+
+	// Listener is set for requests on one of the additional listeners
+	Listener string `json:"listener,omitempty"`
+	// Consumer is set for requests to a consumer profile
+	Consumer string `json:"consumer,omitempty"`
+
+	TokenValidation *tokenValidation `json:"tokenValidation,omitempty"`
+	SignatureValid  *bool            `json:"signatureValid,omitempty"`
+
+	ClientCertificate *clientCertificate `json:"clientCertificate,omitempty"`
+
+	WebSocketMessage *websocketMessage `json:"webSocketMessage,omitempty"`
+}
+
+// newRecord returns the record of a request with the given headers, which need
+// to be in canonical form. The values of sensitive headers are redacted.
+func newRecord(path, method, proto string, headers map[string][]string) *request {
+	traceID, spanID := traceContextFromHeaders(headers)
+	return &request{
+		Time:    time.Now(),
+		Path:    path,
+		Method:  method,
+		Proto:   proto,
+		TraceID: traceID,
+		SpanID:  spanID,
+		Headers: redactHeaders(headers),
+	}
+}
+
+// newRequestRecord returns the record of an HTTP request as it is stored and
+// echoed, without its body.
+func newRequestRecord(c *fiber.Ctx) *request {
+	reqData := newRecord(c.Path(), c.Method(), requestProtocol(c), c.GetReqHeaders())
+	reqData.Listener = listenerName(c)
+	reqData.ClientCertificate = peerCertificate(c)
+	return reqData
 }
@@ -0,0 +1,275 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"cosmoparrot/internal/config"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// websocketSessionKey is the fiber local that carries the websocketSession
+// from the upgrade request to the connection.
+const websocketSessionKey = "cosmoparrot.websocket"
+
+// websocketCloseTimeout bounds how long to wait for the client to answer a
+// close frame.
+const websocketCloseTimeout = time.Second
+
+// websocketSession holds what is known about a connection from its upgrade
+// request.
+type websocketSession struct {
+	key        string
+	delay      time.Duration
+	closeCode  int
+	closeAfter int
+	// upgrade is the request that messages are recorded with
+	upgrade request
+}
+
+// websocketMessage is a message recorded with the upgrade request.
+type websocketMessage struct {
+	// Type is "text" or "binary"
+	Type string `json:"type"`
+	// Direction is "received" for messages of the client and "sent" for
+	// server-initiated ones
+	Direction string `json:"direction"`
+	// Data is the text or the base64 encoded binary data
+	Data string `json:"data"`
+}
+
+// websocketConn is an open connection. Writes are serialized since echoes and
+// server-initiated messages are written concurrently.
+type websocketConn struct {
+	conn    *websocket.Conn
+	session *websocketSession
+	writeMu sync.Mutex
+}
+
+func (c *websocketConn) write(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.conn.WriteMessage(messageType, data)
+}
+
+// websocketHub tracks the open connections by store key, so messages can be
+// sent to them.
+type websocketHub struct {
+	mu    sync.Mutex
+	conns map[string]map[*websocketConn]struct{}
+}
+
+var websockets = &websocketHub{conns: make(map[string]map[*websocketConn]struct{})}
+
+func (h *websocketHub) add(key string, conn *websocketConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.conns[key] == nil {
+		h.conns[key] = make(map[*websocketConn]struct{})
+	}
+	h.conns[key][conn] = struct{}{}
+}
+
+func (h *websocketHub) remove(key string, conn *websocketConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.conns[key], conn)
+	if len(h.conns[key]) == 0 {
+		delete(h.conns, key)
+	}
+}
+
+func (h *websocketHub) get(key string) []*websocketConn {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	conns := make([]*websocketConn, 0, len(h.conns[key]))
+	for conn := range h.conns[key] {
+		conns = append(conns, conn)
+	}
+	return conns
+}
+
+// handleWebsocketUpgrade captures the upgrade request for the connection and
+// rejects requests that are no WebSocket upgrade or cannot be upgraded.
+func handleWebsocketUpgrade(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return fiber.ErrUpgradeRequired
+	}
+	// the connection of requests received via net/http cannot be hijacked
+	if isBridged(c) {
+		return c.Status(fiber.StatusNotImplemented).SendString("WebSocket is not supported on the HTTP/2 listeners")
+	}
+
+	// the connection outlives the request, whose buffers the strings of the
+	// context point into
+	upgrade := newRequestRecord(c)
+	upgrade.Path = strings.Clone(upgrade.Path)
+	headers := make(map[string][]string, len(upgrade.Headers))
+	for name, values := range upgrade.Headers {
+		cloned := make([]string, len(values))
+		for i, value := range values {
+			cloned[i] = strings.Clone(value)
+		}
+		headers[strings.Clone(name)] = cloned
+	}
+	upgrade.Headers = headers
+	c.Locals(websocketSessionKey, &websocketSession{
+		key:        extractStoreKey(c),
+		delay:      getResponseDelay(c),
+		closeCode:  getCloseCode(c),
+		closeAfter: getCloseAfter(c),
+		upgrade:    *upgrade,
+	})
+	return c.Next()
+}
+
+// handleWebsocket echoes the messages of a connection until the client closes
+// it or the requested close code is sent.
+func handleWebsocket(c *websocket.Conn) {
+	session := c.Locals(websocketSessionKey).(*websocketSession)
+	conn := &websocketConn{conn: c, session: session}
+	c.SetReadLimit(int64(config.LoadedConfiguration.BodyLimit))
+
+	websocketActiveConnections.Inc()
+	defer websocketActiveConnections.Dec()
+	if session.key != "" {
+		websockets.add(session.key, conn)
+		defer websockets.remove(session.key, conn)
+	}
+
+	for received := 0; session.closeCode == 0 || received < session.closeAfter; received++ {
+		messageType, data, err := c.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Debug().Err(err).Msg("websocket connection failed")
+			}
+			return
+		}
+		session.record(messageType, data, "received")
+
+		if session.delay > 0 {
+			time.Sleep(session.delay)
+		}
+		if err := conn.write(messageType, data); err != nil {
+			log.Debug().Err(err).Msg("failed to echo websocket message")
+			return
+		}
+	}
+
+	if session.closeAfter == 0 && session.delay > 0 {
+		time.Sleep(session.delay)
+	}
+	conn.close(session.closeCode)
+}
+
+// close sends a close frame and waits for the client to answer it.
+func (c *websocketConn) close(code int) {
+	message := websocket.FormatCloseMessage(code, "")
+	deadline := time.Now().Add(websocketCloseTimeout)
+	if err := c.conn.WriteControl(websocket.CloseMessage, message, deadline); err != nil {
+		return
+	}
+	_ = c.conn.SetReadDeadline(deadline)
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// record stores a message with the upgrade request under the store key.
+func (s *websocketSession) record(messageType int, data []byte, direction string) {
+	if s.key == "" {
+		return
+	}
+	message := &websocketMessage{Type: "text", Direction: direction, Data: string(data)}
+	if messageType == websocket.BinaryMessage {
+		message.Type = "binary"
+		message.Data = base64.StdEncoding.EncodeToString(data)
+	}
+	reqData := s.upgrade
+	reqData.Time = time.Now()
+	reqData.WebSocketMessage = message
+	if _, err := storeRequest(s.key, &reqData); err != nil {
+		log.Error().Err(err).Str("key", s.key).Msg("failed to record websocket message")
+	}
+}
+
+// handleSendWebsocketMessage sends the request body as message to the
+// connections opened with the store key. It is a text message unless
+// ?type=binary is given.
+func handleSendWebsocketMessage(c *fiber.Ctx) error {
+	key := c.Params("key")
+	conns := websockets.get(key)
+	if len(conns) == 0 {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+
+	messageType := websocket.TextMessage
+	switch strings.ToLower(c.Query("type", "text")) {
+	case "text":
+	case "binary":
+		messageType = websocket.BinaryMessage
+	default:
+		return c.Status(fiber.StatusBadRequest).SendString("type must be text or binary")
+	}
+
+	data := c.Body()
+	sent := 0
+	for _, conn := range conns {
+		if err := conn.write(messageType, data); err != nil {
+			log.Debug().Err(err).Str("key", key).Msg("failed to send websocket message")
+			continue
+		}
+		conn.session.record(messageType, data, "sent")
+		sent++
+	}
+	if sent == 0 {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"connections": sent})
+}
+
+// getCloseCode reads the optional "closeCode" query parameter. Returns 0 for
+// missing values and codes that may not be sent in a close frame.
+func getCloseCode(c *fiber.Ctx) int {
+	raw := queryCaseInsensitive(c, "closeCode")
+	if raw == "" {
+		return 0
+	}
+
+	code, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || code < 1000 || code > 4999 {
+		return 0
+	}
+	switch code {
+	case 1004, websocket.CloseNoStatusReceived, websocket.CloseAbnormalClosure, websocket.CloseTLSHandshake:
+		return 0
+	}
+	return code
+}
+
+// getCloseAfter reads the optional "closeAfter" query parameter, the number of
+// messages echoed before the connection is closed. Returns 0 for missing,
+// non-integer or negative values.
+func getCloseAfter(c *fiber.Ctx) int {
+	raw := queryCaseInsensitive(c, "closeAfter")
+	if raw == "" {
+		return 0
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
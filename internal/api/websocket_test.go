@@ -0,0 +1,168 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bytes"
+	"cosmoparrot/internal/cache"
+	"embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	fastws "github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startWebsocketTestServer serves the app on a local listener and returns its
+// WebSocket and HTTP base URLs.
+func startWebsocketTestServer(t *testing.T) (string, string) {
+	t.Helper()
+	app := NewApp(embed.FS{})
+	addr := serveApp(t, app)
+	return "ws://" + addr, "http://" + addr
+}
+
+func dialWebsocket(t *testing.T, url, key string) *fastws.Conn {
+	t.Helper()
+	header := http.Header{}
+	if key != "" {
+		cache.Current.Delete(key)
+		header.Set("X-Request-Key", key)
+	}
+	conn, _, err := fastws.DefaultDialer.Dial(url, header)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	return conn
+}
+
+func storedWebsocketMessages(t *testing.T, key string) []*websocketMessage {
+	t.Helper()
+	stored, found := cache.Current.Get(key)
+	require.True(t, found)
+	var requests []*request
+	require.NoError(t, json.Unmarshal([]byte(stored.(string)), &requests))
+	var messages []*websocketMessage
+	for _, r := range requests {
+		assert.Equal(t, "/api/v1/ws", r.Path)
+		assert.Equal(t, []string{key}, r.Headers["X-Request-Key"])
+		messages = append(messages, r.WebSocketMessage)
+	}
+	return messages
+}
+
+func TestWebsocket_Echo(t *testing.T) {
+	wsURL, _ := startWebsocketTestServer(t)
+	conn := dialWebsocket(t, wsURL+"/api/v1/ws", "ws-echo")
+
+	require.NoError(t, conn.WriteMessage(fastws.TextMessage, []byte("hello")))
+	messageType, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, fastws.TextMessage, messageType)
+	assert.Equal(t, "hello", string(data))
+
+	require.NoError(t, conn.WriteMessage(fastws.BinaryMessage, []byte{0, 1, 2}))
+	messageType, data, err = conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, fastws.BinaryMessage, messageType)
+	assert.Equal(t, []byte{0, 1, 2}, data)
+
+	assert.Equal(t, []*websocketMessage{
+		{Type: "text", Direction: "received", Data: "hello"},
+		{Type: "binary", Direction: "received", Data: "AAEC"},
+	}, storedWebsocketMessages(t, "ws-echo"))
+}
+
+func TestWebsocket_CloseCode(t *testing.T) {
+	wsURL, _ := startWebsocketTestServer(t)
+
+	conn := dialWebsocket(t, wsURL+"/api/v1/ws?closeCode=4001", "")
+	_, _, err := conn.ReadMessage()
+	assert.True(t, fastws.IsCloseError(err, 4001), err)
+
+	conn = dialWebsocket(t, wsURL+"/api/v1/ws?closeCode=1011&closeAfter=1&responseDelay=50", "")
+	start := time.Now()
+	require.NoError(t, conn.WriteMessage(fastws.TextMessage, []byte("last")))
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "last", string(data))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	_, _, err = conn.ReadMessage()
+	assert.True(t, fastws.IsCloseError(err, fastws.CloseInternalServerErr), err)
+}
+
+func TestWebsocket_ServerInitiatedMessages(t *testing.T) {
+	wsURL, httpURL := startWebsocketTestServer(t)
+	conn := dialWebsocket(t, wsURL+"/api/v1/ws", "ws-push")
+
+	// the connection is registered once the handshake completed
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		var err error
+		resp, err = http.Post(httpURL+"/api/v1/ws/ws-push?type=binary", "application/octet-stream", bytes.NewReader([]byte("push")))
+		require.NoError(t, err)
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return false
+		}
+		return true
+	}, time.Second, 10*time.Millisecond)
+	defer resp.Body.Close()
+	var result map[string]int
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, 1, result["connections"])
+
+	messageType, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, fastws.BinaryMessage, messageType)
+	assert.Equal(t, "push", string(data))
+	assert.Equal(t, []*websocketMessage{
+		{Type: "binary", Direction: "sent", Data: "cHVzaA=="},
+	}, storedWebsocketMessages(t, "ws-push"))
+
+	resp, err = http.Post(httpURL+"/api/v1/ws/unknown", "text/plain", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestWebsocket_UpgradeRequired(t *testing.T) {
+	app := NewApp(embed.FS{})
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/ws", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUpgradeRequired, resp.StatusCode)
+}
+
+func TestGetCloseCode(t *testing.T) {
+	cases := map[string]int{"": 0, "1000": 1000, "4999": 4999, "999": 0, "5000": 0, "1005": 0, "1006": 0, "abc": 0}
+	for raw, expected := range cases {
+		app := fiber.New()
+		var result int
+		app.Get("/test", func(c *fiber.Ctx) error {
+			result = getCloseCode(c)
+			return c.SendStatus(http.StatusOK)
+		})
+
+		_, err := app.Test(httptest.NewRequest(http.MethodGet, "/test?closeCode="+raw, nil))
+		require.NoError(t, err)
+		assert.Equal(t, expected, result, raw)
+	}
+}
+
+func TestWebsocket_HTTP2Listener(t *testing.T) {
+	restoreConfig(t)
+	app, _ := newApp(embed.FS{})
+	addr := serveHTTP2(t, newHTTP2Server(app, nil), false)
+
+	_, resp, err := fastws.DefaultDialer.Dial("ws://"+addr+"/api/v1/ws", nil)
+	require.ErrorIs(t, err, fastws.ErrBadHandshake)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+}
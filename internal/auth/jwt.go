@@ -0,0 +1,72 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrNoMatchingKey is returned when the key set has no key for a token.
+var ErrNoMatchingKey = errors.New("no matching key in JWKS")
+
+// signingMethods are the asymmetric algorithms accepted for JWTs. Symmetric
+// algorithms are rejected, since the key set only holds public keys.
+var signingMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// Verifier validates JWTs against a key set. Issuer and audience are only
+// checked if they are set.
+type Verifier struct {
+	Keys     *KeySet
+	Issuer   string
+	Audience string
+}
+
+// Verify checks the signature and the registered claims of a token and returns
+// its claims. Tokens must expire. Errors wrap the jwt package errors, e.g.
+// jwt.ErrTokenExpired.
+func (v *Verifier) Verify(token string) (jwt.MapClaims, error) {
+	options := []jwt.ParserOption{jwt.WithValidMethods(signingMethods), jwt.WithExpirationRequired()}
+	if v.Issuer != "" {
+		options = append(options, jwt.WithIssuer(v.Issuer))
+	}
+	if v.Audience != "" {
+		options = append(options, jwt.WithAudience(v.Audience))
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, v.keyfunc, options...); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// keyfunc selects the key by the "kid" header of the token. Tokens without a
+// key id are tried against every key of the set.
+func (v *Verifier) keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	keys := jwt.VerificationKeySet{}
+	for _, jwk := range v.Keys.Keys {
+		if kid != "" && jwk.Kid != kid {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		keys.Keys = append(keys.Keys, key)
+	}
+	if len(keys.Keys) == 0 {
+		return nil, fmt.Errorf("%w for kid %q", ErrNoMatchingKey, kid)
+	}
+	return keys, nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sign(t *testing.T, method jwt.SigningMethod, key crypto.Signer, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func keySet(t *testing.T, keys map[string]crypto.Signer) *KeySet {
+	t.Helper()
+	set := &KeySet{}
+	for kid, key := range keys {
+		jwk, err := NewJWK(key.Public(), kid, "")
+		require.NoError(t, err)
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func TestVerifier_KeyTypes(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	verifier := &Verifier{Keys: keySet(t, map[string]crypto.Signer{"rsa": rsaKey, "ec": ecKey, "ed": edKey})}
+	claims := jwt.MapClaims{"sub": "consumer", "exp": time.Now().Add(time.Minute).Unix()}
+
+	for _, token := range []string{
+		sign(t, jwt.SigningMethodRS256, rsaKey, "rsa", claims),
+		sign(t, jwt.SigningMethodPS512, rsaKey, "rsa", claims),
+		sign(t, jwt.SigningMethodES384, ecKey, "ec", claims),
+		sign(t, jwt.SigningMethodEdDSA, edKey, "ed", claims),
+		// without a key id every key is tried
+		sign(t, jwt.SigningMethodES384, ecKey, "", claims),
+	} {
+		verified, err := verifier.Verify(token)
+		assert.NoError(t, err)
+		assert.Equal(t, "consumer", verified["sub"])
+	}
+}
+
+func TestVerifier_Rejects(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	verifier := &Verifier{Keys: keySet(t, map[string]crypto.Signer{"key": key}), Issuer: "issuer", Audience: "parrot"}
+	exp := time.Now().Add(time.Minute).Unix()
+	valid := jwt.MapClaims{"iss": "issuer", "aud": "parrot", "exp": exp}
+
+	_, err = verifier.Verify(sign(t, jwt.SigningMethodES256, key, "key", valid))
+	assert.NoError(t, err)
+
+	_, err = verifier.Verify(sign(t, jwt.SigningMethodES256, other, "key", valid))
+	assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+
+	_, err = verifier.Verify(sign(t, jwt.SigningMethodES256, key, "unknown", valid))
+	assert.ErrorIs(t, err, ErrNoMatchingKey)
+
+	_, err = verifier.Verify(sign(t, jwt.SigningMethodES256, key, "key", jwt.MapClaims{"iss": "other", "aud": "parrot", "exp": exp}))
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidIssuer)
+
+	_, err = verifier.Verify(sign(t, jwt.SigningMethodES256, key, "key", jwt.MapClaims{"iss": "issuer", "aud": "other", "exp": exp}))
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+
+	expired := jwt.MapClaims{"iss": "issuer", "aud": "parrot", "exp": time.Now().Add(-time.Minute).Unix()}
+	_, err = verifier.Verify(sign(t, jwt.SigningMethodES256, key, "key", expired))
+	assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+
+	_, err = verifier.Verify(sign(t, jwt.SigningMethodES256, key, "key", jwt.MapClaims{"iss": "issuer", "aud": "parrot"}))
+	assert.ErrorIs(t, err, jwt.ErrTokenRequiredClaimMissing)
+
+	hmac, err := jwt.NewWithClaims(jwt.SigningMethodHS256, valid).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = verifier.Verify(hmac)
+	assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+}
+
+func TestLoadKeySet(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	data, err := json.Marshal(keySet(t, map[string]crypto.Signer{"rsa": key}))
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	set, err := LoadKeySet(path)
+	require.NoError(t, err)
+	public, err := set.Keys[0].PublicKey()
+	require.NoError(t, err)
+	assert.True(t, key.PublicKey.Equal(public))
+
+	_, err = ParseKeySet([]byte(`{"keys":[]}`))
+	assert.Error(t, err)
+	_, err = ParseKeySet([]byte(`{"keys":[{"kty":"EC","crv":"P-256","x":"AA","y":"AA"}]}`))
+	assert.Error(t, err)
+	_, err = LoadKeySet(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
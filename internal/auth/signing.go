@@ -0,0 +1,118 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is a private key that signs JWTs, along with its key id.
+type SigningKey struct {
+	Signer crypto.Signer
+	Kid    string
+}
+
+// GenerateSigningKey creates an RSA signing key, which every JWT library can verify.
+func GenerateSigningKey() (*SigningKey, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return newSigningKey(key)
+}
+
+// LoadSigningKey reads a PEM encoded RSA, EC or Ed25519 private key in PKCS #8,
+// PKCS #1 or SEC 1 form.
+func LoadSigningKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("signing key is not PEM encoded")
+	}
+
+	var key any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing key type %T", key)
+	}
+	return newSigningKey(signer)
+}
+
+// newSigningKey derives the key id from the public key, so the same key
+// always gets the same id.
+func newSigningKey(signer crypto.Signer) (*SigningKey, error) {
+	der, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+	key := &SigningKey{Signer: signer, Kid: encode(sum[:12])}
+	if key.Method() == nil {
+		return nil, fmt.Errorf("unsupported signing key type %T", signer)
+	}
+	return key, nil
+}
+
+// Method returns the signing method matching the key type.
+func (k *SigningKey) Method() jwt.SigningMethod {
+	switch key := k.Signer.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256
+	case *ecdsa.PrivateKey:
+		switch key.Curve.Params().BitSize {
+		case 256:
+			return jwt.SigningMethodES256
+		case 384:
+			return jwt.SigningMethodES384
+		case 521:
+			return jwt.SigningMethodES512
+		}
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA
+	}
+	return nil
+}
+
+// Sign issues a JWT with the given claims.
+func (k *SigningKey) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.Method(), claims)
+	token.Header["kid"] = k.Kid
+	return token.SignedString(k.Signer)
+}
+
+// KeySet returns the JWKS that verifies the tokens signed with the key.
+func (k *SigningKey) KeySet() (*KeySet, error) {
+	jwk, err := NewJWK(k.Signer.Public(), k.Kid, k.Method().Alg())
+	if err != nil {
+		return nil, err
+	}
+	return &KeySet{Keys: []JWK{jwk}}, nil
+}
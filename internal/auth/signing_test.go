@@ -0,0 +1,78 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		path   string
+		method jwt.SigningMethod
+	}{
+		{writePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)), jwt.SigningMethodRS256},
+		{writePEM(t, "EC PRIVATE KEY", ecDER), jwt.SigningMethodES384},
+		{writePEM(t, "PRIVATE KEY", edDER), jwt.SigningMethodEdDSA},
+	}
+	for _, tt := range tests {
+		key, err := LoadSigningKey(tt.path)
+		require.NoError(t, err)
+		assert.Equal(t, tt.method, key.Method())
+
+		token, err := key.Sign(jwt.MapClaims{"sub": "producer", "exp": time.Now().Add(time.Minute).Unix()})
+		require.NoError(t, err)
+		keys, err := key.KeySet()
+		require.NoError(t, err)
+		claims, err := (&Verifier{Keys: keys}).Verify(token)
+		require.NoError(t, err)
+		assert.Equal(t, "producer", claims["sub"])
+	}
+
+	_, err = LoadSigningKey(writePEM(t, "PRIVATE KEY", []byte("garbage")))
+	assert.Error(t, err)
+}
+
+func TestGenerateSigningKey_StableKid(t *testing.T) {
+	key, err := GenerateSigningKey()
+	require.NoError(t, err)
+
+	again, err := newSigningKey(key.Signer)
+	require.NoError(t, err)
+	assert.Equal(t, key.Kid, again.Kid)
+	assert.Equal(t, jwt.SigningMethodRS256, key.Method())
+}
@@ -6,11 +6,33 @@ package cache
 
 import (
 	go_cache "github.com/patrickmn/go-cache"
+	"sync/atomic"
 	"time"
 )
 
 var Current *go_cache.Cache
 
+// TraceIndex maps trace ids to the keys of Current under which requests of that
+// trace are stored. Trace ids are never used as keys of Current itself.
+var TraceIndex *go_cache.Cache
+
+var evictions atomic.Uint64
+
 func init() {
-	Current = go_cache.New(1*time.Hour, 10*time.Minute)
+	Current = newStore(1*time.Hour, 10*time.Minute)
+	TraceIndex = go_cache.New(1*time.Hour, 10*time.Minute)
+}
+
+func newStore(defaultExpiration, cleanupInterval time.Duration) *go_cache.Cache {
+	store := go_cache.New(defaultExpiration, cleanupInterval)
+	store.OnEvicted(func(string, interface{}) {
+		evictions.Add(1)
+	})
+	return store
+}
+
+// Evictions returns how many entries have been removed from the store since
+// startup, either because they expired or because they were deleted.
+func Evictions() uint64 {
+	return evictions.Load()
 }
@@ -74,3 +74,17 @@ func TestCacheDelete(t *testing.T) {
 	_, found = Current.Get(key)
 	assert.False(t, found, "Key should not exist in cache after deletion")
 }
+
+func TestEvictionsCounted(t *testing.T) {
+	Current = newStore(50*time.Millisecond, 10*time.Millisecond)
+	before := Evictions()
+
+	Current.Set("expiring", "value", cache.DefaultExpiration)
+	Current.Set("deleted", "value", cache.NoExpiration)
+	Current.Delete("deleted")
+
+	// Wait for the janitor to remove the expired entry
+	time.Sleep(100 * time.Millisecond)
+
+	assert.Equal(t, before+2, Evictions())
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package certs issues X.509 certificates for the TLS listeners, so no
+// certificates have to be provisioned for testing.
+package certs
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"time"
+)
+
+const organization = "Cosmoparrot"
+
+// Authority is a certificate authority that issues leaf certificates.
+type Authority struct {
+	Cert *x509.Certificate
+	Key  crypto.Signer
+}
+
+// LeafOptions describe a leaf certificate. Zero times default to a validity
+// from an hour ago until a year from now.
+type LeafOptions struct {
+	CommonName string
+	Hosts      []string
+	NotBefore  time.Time
+	NotAfter   time.Time
+}
+
+// NewAuthority creates a self-signed CA that is valid for ten years.
+func NewAuthority(commonName string) (*Authority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{organization}, CommonName: commonName},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &Authority{Cert: cert, Key: key}, nil
+}
+
+// PEM returns the PEM encoded CA certificate, e.g. for trust stores.
+func (a *Authority) PEM() []byte {
+	return encodeCertificate(a.Cert.Raw)
+}
+
+// Issue creates a leaf certificate that can be used by servers and clients.
+func (a *Authority) Issue(options LeafOptions) (tls.Certificate, error) {
+	return issue(options, a.Cert, a.Key)
+}
+
+// SelfSigned creates a leaf certificate that is signed by its own key and
+// therefore trusted by nobody.
+func SelfSigned(options LeafOptions) (tls.Certificate, error) {
+	return issue(options, nil, nil)
+}
+
+func issue(options LeafOptions, parent *x509.Certificate, parentKey crypto.Signer) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{organization}, CommonName: options.CommonName},
+		NotBefore:    options.NotBefore,
+		NotAfter:     options.NotAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if template.NotBefore.IsZero() {
+		template.NotBefore = now.Add(-time.Hour)
+	}
+	if template.NotAfter.IsZero() {
+		template.NotAfter = now.AddDate(1, 0, 0)
+	}
+	for _, host := range options.Hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	if template.Subject.CommonName == "" && len(options.Hosts) > 0 {
+		template.Subject.CommonName = options.Hosts[0]
+	}
+
+	selfSigned := parent == nil
+	if selfSigned {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	// the chain includes the CA, clients still need to trust it
+	chain := [][]byte{der}
+	if !selfSigned {
+		chain = append(chain, parent.Raw)
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: chain, PrivateKey: key, Leaf: leaf}, nil
+}
+
+func newSerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+func encodeCertificate(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
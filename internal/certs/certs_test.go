@@ -0,0 +1,47 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package certs
+
+import (
+	"crypto/x509"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthority_Issue(t *testing.T) {
+	ca, err := NewAuthority("Test CA")
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(ca.PEM()))
+
+	cert, err := ca.Issue(LeafOptions{Hosts: []string{"parrot.example", "127.0.0.1"}})
+	require.NoError(t, err)
+	assert.Len(t, cert.Certificate, 2)
+	assert.Equal(t, "parrot.example", cert.Leaf.Subject.CommonName)
+
+	for _, host := range []string{"parrot.example", "127.0.0.1"} {
+		_, err = cert.Leaf.Verify(x509.VerifyOptions{DNSName: host, Roots: pool})
+		assert.NoError(t, err, host)
+	}
+	_, err = cert.Leaf.Verify(x509.VerifyOptions{DNSName: "other.example", Roots: pool})
+	assert.Error(t, err)
+}
+
+func TestSelfSigned(t *testing.T) {
+	ca, err := NewAuthority("Test CA")
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+
+	cert, err := SelfSigned(LeafOptions{Hosts: []string{"localhost"}})
+	require.NoError(t, err)
+	assert.Len(t, cert.Certificate, 1)
+	assert.Equal(t, cert.Leaf.Subject, cert.Leaf.Issuer)
+
+	_, err = cert.Leaf.Verify(x509.VerifyOptions{DNSName: "localhost", Roots: pool})
+	assert.Error(t, err)
+}
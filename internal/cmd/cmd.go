@@ -0,0 +1,100 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cmd implements the command-line interface of the cosmoparrot binary.
+package cmd
+
+import (
+	"cosmoparrot/internal/api"
+	"cosmoparrot/internal/config"
+	"cosmoparrot/internal/memlimit"
+	"embed"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+)
+
+// NewRootCommand builds the cosmoparrot command tree. Running the root command
+// without a subcommand starts the server, just like "serve".
+func NewRootCommand(webDir embed.FS, version string) *cobra.Command {
+	var configPath string
+
+	serve := func(cmd *cobra.Command, args []string) error {
+		if err := config.LoadedConfiguration.Validate(); err != nil {
+			return err
+		}
+		memlimit.Configure()
+		api.Listen(webDir)
+		return nil
+	}
+
+	root := &cobra.Command{
+		Use:           "cosmoparrot",
+		Short:         "A simple HTTP based echo server",
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return config.Load(configPath, cmd.Flags())
+		},
+		RunE: serve,
+	}
+	root.PersistentFlags().StringVar(&configPath, "config", "", "path to a configuration file (default ./config.yml)")
+	config.RegisterFlags(root.PersistentFlags())
+
+	root.AddCommand(
+		&cobra.Command{
+			Use:   "serve",
+			Short: "Start the echo server",
+			Args:  cobra.NoArgs,
+			RunE:  serve,
+		},
+		&cobra.Command{
+			Use:   "version",
+			Short: "Print the version",
+			Args:  cobra.NoArgs,
+			// The version does not depend on the configuration.
+			PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+			Run: func(cmd *cobra.Command, args []string) {
+				fmt.Fprintln(cmd.OutOrStdout(), version)
+			},
+		},
+		&cobra.Command{
+			Use:   "print-config",
+			Short: "Print the effective configuration as YAML",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				settings, err := config.LoadedConfiguration.Settings()
+				if err != nil {
+					return err
+				}
+				encoder := yaml.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent(2)
+				if err := encoder.Encode(settings); err != nil {
+					return err
+				}
+				return encoder.Close()
+			},
+		},
+		&cobra.Command{
+			Use:   "check-config",
+			Short: "Validate the configuration and exit",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if err := config.LoadedConfiguration.Validate(); err != nil {
+					return err
+				}
+				source := config.ConfigFileUsed()
+				if source == "" {
+					source = "defaults and environment"
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "configuration from %s is valid\n", source)
+				return nil
+			},
+		},
+	)
+
+	return root
+}
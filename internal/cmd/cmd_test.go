@@ -0,0 +1,60 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"embed"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.yaml.in/yaml/v3"
+)
+
+func execute(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	root := NewRootCommand(embed.FS{}, "1.2.3")
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs(args)
+	err := root.Execute()
+	return out.String(), err
+}
+
+func TestVersion(t *testing.T) {
+	out, err := execute(t, "version")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3\n", out)
+}
+
+func TestPrintConfig_FlagOverridesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	err := os.WriteFile(path, []byte("port: 7070\nresponseCode: 202\n"), 0o600)
+	assert.NoError(t, err)
+
+	out, err := execute(t, "print-config", "--config", path, "--response-code", "503")
+	assert.NoError(t, err)
+
+	var printed map[string]any
+	assert.NoError(t, yaml.Unmarshal([]byte(out), &printed))
+	assert.Equal(t, 7070, printed["port"])
+	assert.Equal(t, 503, printed["responseCode"])
+	assert.Equal(t, []any{"x-request-key"}, printed["storeKeyRequestHeaders"])
+}
+
+func TestCheckConfig(t *testing.T) {
+	out, err := execute(t, "check-config")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "is valid")
+
+	_, err = execute(t, "check-config", "--port", "70000")
+	assert.ErrorContains(t, err, "port 70000 is out of range")
+
+	_, err = execute(t, "check-config", "--config", filepath.Join(t.TempDir(), "missing.yml"))
+	assert.Error(t, err)
+}
@@ -5,30 +5,135 @@
 package config
 
 import (
+	"cosmoparrot/internal/auth"
+	"cosmoparrot/internal/logging"
+	"crypto/tls"
 	"errors"
+	"fmt"
+	"path"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/gofiber/fiber/v2/log"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
 var LoadedConfiguration configuration
 
+var supportedPropagators = []string{"tracecontext", "baggage", "b3", "b3multi", "jaeger"}
+
+var supportedExporterProtocols = []string{"grpc", "http/protobuf", "stdout", "file"}
+
+var supportedSignatureAlgorithms = []string{"sha1", "sha256", "sha384", "sha512"}
+
+// secretSettings are the settings Settings masks, so printing the configuration
+// does not reveal credentials.
+var secretSettings = []string{
+	"redactionHashKey",
+	"apiAuthToken",
+	"apiAuthPassword",
+	"oauthClients",
+	"signatureSecret",
+}
+
+const maskedSetting = "*****"
+
+var supportedSamplers = []string{
+	"always_on", "always_off", "traceidratio",
+	"parentbased_always_on", "parentbased_always_off", "parentbased_traceidratio",
+}
+
+// init applies the defaults only. The configuration file, environment variables
+// and flags are read by Load, so a malformed file is reported as an error instead
+// of a panic before the command line is parsed.
 func init() {
 	setDefaults()
-	loadConfiguration()
+	if err := viper.Unmarshal(&LoadedConfiguration); err != nil {
+		panic(err)
+	}
+	LoadedConfiguration.BuildMethodResponseCodeMap()
 }
 
 type configuration struct {
 	LogLevel                        string         `mapstructure:"logLevel"`
+	LogFormat                       string         `mapstructure:"logFormat"`
 	Port                            int            `mapstructure:"port"`
+	TLSEnabled                      bool           `mapstructure:"tlsEnabled"`
+	TLSPort                         int            `mapstructure:"tlsPort"`
+	TLSCertFile                     string         `mapstructure:"tlsCertFile"`
+	TLSKeyFile                      string         `mapstructure:"tlsKeyFile"`
+	TLSHostnames                    []string       `mapstructure:"tlsHostnames"`
+	TLSClientAuth                   string         `mapstructure:"tlsClientAuth"`
+	TLSClientCAFile                 string         `mapstructure:"tlsClientCaFile"`
+	TLSExpiredPort                  int            `mapstructure:"tlsExpiredPort"`
+	TLSWrongHostnamePort            int            `mapstructure:"tlsWrongHostnamePort"`
+	TLSSelfSignedPort               int            `mapstructure:"tlsSelfSignedPort"`
+	TLSLegacyPort                   int            `mapstructure:"tlsLegacyPort"`
+	HTTP2Port                       int            `mapstructure:"http2Port"`
+	HTTP2TLSPort                    int            `mapstructure:"http2TlsPort"`
+	GRPCPort                        int            `mapstructure:"grpcPort"`
+	Listeners                       []string       `mapstructure:"listeners"`
 	ResponseCode                    int            `mapstructure:"responseCode"`
 	MethodResponseCodeMapping       []string       `mapstructure:"methodResponseCodeMapping"`
 	RequestLogging                  bool           `mapstructure:"requestLogging"`
+	RequestLogSampleRate            int            `mapstructure:"requestLogSampleRate"`
+	RequestLogRateLimit             int            `mapstructure:"requestLogRateLimit"`
+	RequestLogErrorsOnly            bool           `mapstructure:"requestLogErrorsOnly"`
+	RequestLogIncludePaths          []string       `mapstructure:"requestLogIncludePaths"`
+	RequestLogExcludePaths          []string       `mapstructure:"requestLogExcludePaths"`
+	RequestLogBody                  bool           `mapstructure:"requestLogBody"`
+	RequestLogBodyMaxSize           int            `mapstructure:"requestLogBodyMaxSize"`
+	RedactedHeaders                 []string       `mapstructure:"redactedHeaders"`
+	RedactionMode                   string         `mapstructure:"redactionMode"`
+	RedactionHashKey                string         `mapstructure:"redactionHashKey"`
 	ReadBufferSize                  int            `mapstructure:"readBufferSize"`
+	WriteBufferSize                 int            `mapstructure:"writeBufferSize"`
+	BodyLimit                       int            `mapstructure:"bodyLimit"`
+	ReadTimeout                     time.Duration  `mapstructure:"readTimeout"`
+	WriteTimeout                    time.Duration  `mapstructure:"writeTimeout"`
+	IdleTimeout                     time.Duration  `mapstructure:"idleTimeout"`
+	Concurrency                     int            `mapstructure:"concurrency"`
+	DisableKeepalive                bool           `mapstructure:"disableKeepalive"`
+	APIAuthMode                     string         `mapstructure:"apiAuthMode"`
+	APIAuthToken                    string         `mapstructure:"apiAuthToken"`
+	APIAuthUsername                 string         `mapstructure:"apiAuthUsername"`
+	APIAuthPassword                 string         `mapstructure:"apiAuthPassword"`
+	APIAuthJwksFile                 string         `mapstructure:"apiAuthJwksFile"`
+	APIAuthJwtIssuer                string         `mapstructure:"apiAuthJwtIssuer"`
+	APIAuthJwtAudience              string         `mapstructure:"apiAuthJwtAudience"`
+	EchoJwtEnabled                  bool           `mapstructure:"echoJwtEnabled"`
+	EchoJwtJwksFile                 string         `mapstructure:"echoJwtJwksFile"`
+	EchoJwtIssuer                   string         `mapstructure:"echoJwtIssuer"`
+	EchoJwtAudience                 string         `mapstructure:"echoJwtAudience"`
+	EchoJwtRequiredScopes           []string       `mapstructure:"echoJwtRequiredScopes"`
+	OAuthEnabled                    bool           `mapstructure:"oauthEnabled"`
+	OAuthSigningKeyFile             string         `mapstructure:"oauthSigningKeyFile"`
+	OAuthIssuer                     string         `mapstructure:"oauthIssuer"`
+	OAuthAudience                   string         `mapstructure:"oauthAudience"`
+	OAuthTokenExpiry                time.Duration  `mapstructure:"oauthTokenExpiry"`
+	OAuthClients                    []string       `mapstructure:"oauthClients"`
+	OAuthClaims                     []string       `mapstructure:"oauthClaims"`
+	SignatureEnabled                bool           `mapstructure:"signatureEnabled"`
+	SignatureAlgorithm              string         `mapstructure:"signatureAlgorithm"`
+	SignatureHeader                 string         `mapstructure:"signatureHeader"`
+	SignatureSecret                 string         `mapstructure:"signatureSecret"`
+	SignatureTimestampHeader        string         `mapstructure:"signatureTimestampHeader"`
+	SignatureTimestampTolerance     time.Duration  `mapstructure:"signatureTimestampTolerance"`
+	SignatureFailureResponseCode    int            `mapstructure:"signatureFailureResponseCode"`
+	MetricsEnabled                  bool           `mapstructure:"metricsEnabled"`
 	OTelEnabled                     bool           `mapstructure:"otelEnabled"`
 	OTelServiceName                 string         `mapstructure:"otelServiceName"`
+	OTelPropagators                 []string       `mapstructure:"otelPropagators"`
+	OTelExporterProtocol            string         `mapstructure:"otelExporterProtocol"`
+	OTelExporterFile                string         `mapstructure:"otelExporterFile"`
+	OTelSampler                     string         `mapstructure:"otelSampler"`
+	OTelSamplerRatio                float64        `mapstructure:"otelSamplerRatio"`
+	OTelMetricsEnabled              bool           `mapstructure:"otelMetricsEnabled"`
+	OTelLogsEnabled                 bool           `mapstructure:"otelLogsEnabled"`
 	StoreKeyRequestHeaders          []string       `mapstructure:"storeKeyRequestHeaders"`
 	SlowlorisDefaultDurationSeconds int            `mapstructure:"slowlorisDefaultDurationSeconds"`
 	SlowlorisDefaultIntervalSeconds int            `mapstructure:"slowlorisDefaultIntervalSeconds"`
@@ -37,41 +142,372 @@ type configuration struct {
 
 func setDefaults() {
 	viper.SetDefault("logLevel", "info")
+	viper.SetDefault("logFormat", "json")
 	viper.SetDefault("port", 8080)
+	viper.SetDefault("tlsEnabled", false)
+	viper.SetDefault("tlsPort", 8443)
+	viper.SetDefault("tlsCertFile", "")
+	viper.SetDefault("tlsKeyFile", "")
+	viper.SetDefault("tlsHostnames", []string{"localhost", "127.0.0.1", "::1"})
+	viper.SetDefault("tlsClientAuth", "none")
+	viper.SetDefault("tlsClientCaFile", "")
+	viper.SetDefault("tlsExpiredPort", 0)
+	viper.SetDefault("tlsWrongHostnamePort", 0)
+	viper.SetDefault("tlsSelfSignedPort", 0)
+	viper.SetDefault("tlsLegacyPort", 0)
+	viper.SetDefault("http2Port", 0)
+	viper.SetDefault("http2TlsPort", 0)
+	viper.SetDefault("grpcPort", 0)
+	viper.SetDefault("listeners", []string{})
 	viper.SetDefault("responseCode", 200)
 	viper.SetDefault("methodResponseCodeMapping", []string{})
 	viper.SetDefault("requestLogging", true)
+	viper.SetDefault("requestLogSampleRate", 1)
+	viper.SetDefault("requestLogRateLimit", 0)
+	viper.SetDefault("requestLogErrorsOnly", false)
+	viper.SetDefault("requestLogIncludePaths", []string{})
+	viper.SetDefault("requestLogExcludePaths", []string{})
+	viper.SetDefault("requestLogBody", false)
+	viper.SetDefault("requestLogBodyMaxSize", 1024)
+	viper.SetDefault("redactedHeaders", []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"})
+	viper.SetDefault("redactionMode", "mask")
+	viper.SetDefault("redactionHashKey", "")
 	viper.SetDefault("readBufferSize", 4096)
+	viper.SetDefault("writeBufferSize", 4096)
+	viper.SetDefault("bodyLimit", 4*1024*1024)
+	viper.SetDefault("readTimeout", 0)
+	viper.SetDefault("writeTimeout", 0)
+	viper.SetDefault("idleTimeout", 0)
+	viper.SetDefault("concurrency", 256*1024)
+	viper.SetDefault("disableKeepalive", false)
 	viper.SetDefault("storeKeyRequestHeaders", []string{"x-request-key"})
+	viper.SetDefault("apiAuthMode", "none")
+	viper.SetDefault("apiAuthToken", "")
+	viper.SetDefault("apiAuthUsername", "")
+	viper.SetDefault("apiAuthPassword", "")
+	viper.SetDefault("apiAuthJwksFile", "")
+	viper.SetDefault("apiAuthJwtIssuer", "")
+	viper.SetDefault("apiAuthJwtAudience", "")
+	viper.SetDefault("echoJwtEnabled", false)
+	viper.SetDefault("echoJwtJwksFile", "")
+	viper.SetDefault("echoJwtIssuer", "")
+	viper.SetDefault("echoJwtAudience", "")
+	viper.SetDefault("echoJwtRequiredScopes", []string{})
+	viper.SetDefault("oauthEnabled", false)
+	viper.SetDefault("oauthSigningKeyFile", "")
+	viper.SetDefault("oauthIssuer", "")
+	viper.SetDefault("oauthAudience", "")
+	viper.SetDefault("oauthTokenExpiry", 5*time.Minute)
+	viper.SetDefault("oauthClients", []string{})
+	viper.SetDefault("oauthClaims", []string{})
+	viper.SetDefault("signatureEnabled", false)
+	viper.SetDefault("signatureAlgorithm", "sha256")
+	viper.SetDefault("signatureHeader", "X-Signature")
+	viper.SetDefault("signatureSecret", "")
+	viper.SetDefault("signatureTimestampHeader", "")
+	viper.SetDefault("signatureTimestampTolerance", 5*time.Minute)
+	viper.SetDefault("signatureFailureResponseCode", 401)
+	viper.SetDefault("metricsEnabled", true)
 	viper.SetDefault("otelEnabled", false)
 	viper.SetDefault("otelServiceName", "cosmoparrot")
+	viper.SetDefault("otelPropagators", []string{"tracecontext"})
+	viper.SetDefault("otelExporterProtocol", "")
+	viper.SetDefault("otelExporterFile", "")
+	viper.SetDefault("otelSampler", "parentbased_always_on")
+	viper.SetDefault("otelSamplerRatio", 1.0)
+	viper.SetDefault("otelMetricsEnabled", false)
+	viper.SetDefault("otelLogsEnabled", false)
 	viper.SetDefault("slowlorisDefaultDurationSeconds", 15)
 	viper.SetDefault("slowlorisDefaultIntervalSeconds", 1)
 }
 
 func loadConfiguration() {
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
+	if err := Load("", nil); err != nil {
+		panic(err)
+	}
+}
+
+// Load (re)reads the configuration into LoadedConfiguration. Values are taken from
+// the given command-line flags, environment variables, the configuration file and
+// the defaults, in that order of precedence. If path is empty, a "config.yml" in the
+// working directory is used when present; otherwise the file at path must exist.
+func Load(path string, flags *pflag.FlagSet) error {
+	viper.Reset()
+	setDefaults()
+
+	if path != "" {
+		viper.SetConfigFile(path)
+	} else {
+		viper.SetConfigName("config")
+		viper.SetConfigType("yaml")
+		viper.AddConfigPath(".")
+	}
 
 	viper.SetEnvPrefix("COSMOPARROT")
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
+	if err := bindFlags(flags); err != nil {
+		return err
+	}
+
+	configFileFound := true
 	if err := viper.ReadInConfig(); err != nil {
 		var configFileNotFoundError viper.ConfigFileNotFoundError
-		if errors.As(err, &configFileNotFoundError) {
-			log.Info("configuration not found but environment variables will be taken into account.")
+		if !errors.As(err, &configFileNotFoundError) {
+			return fmt.Errorf("failed to read configuration file: %w", err)
 		}
+		configFileFound = false
 	}
 
 	viper.AutomaticEnv()
 
-	if err := viper.Unmarshal(&LoadedConfiguration); err != nil {
-		panic(err)
+	var loaded configuration
+	if err := viper.Unmarshal(&loaded); err != nil {
+		return fmt.Errorf("failed to decode configuration: %w", err)
 	}
 
-	LoadedConfiguration.BuildMethodResponseCodeMap()
-	log.SetLevel(parseLogLevel(LoadedConfiguration.LogLevel))
+	loaded.BuildMethodResponseCodeMap()
+	LoadedConfiguration = loaded
+	logging.Configure(LoadedConfiguration.LogLevel, LoadedConfiguration.LogFormat)
+
+	if !configFileFound {
+		log.Info().Msg("configuration not found but environment variables will be taken into account.")
+	}
+
+	return nil
+}
+
+// ConfigFileUsed returns the path of the configuration file that was read by the
+// last call to Load, or "" if no file was found.
+func ConfigFileUsed() string {
+	return viper.ConfigFileUsed()
+}
+
+// Validate reports every setting of the configuration that cannot be applied.
+func (c *configuration) Validate() error {
+	var errs []error
+
+	if c.Port < 1 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("port %d is out of range", c.Port))
+	}
+	if c.TLSEnabled {
+		if c.TLSPort < 1 || c.TLSPort > 65535 || c.TLSPort == c.Port {
+			errs = append(errs, fmt.Errorf("tlsPort %d is out of range or already used", c.TLSPort))
+		}
+		if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+			errs = append(errs, fmt.Errorf("tlsCertFile and tlsKeyFile must be set together"))
+		} else if c.TLSCertFile != "" {
+			if _, err := tls.LoadX509KeyPair(c.TLSCertFile, c.TLSKeyFile); err != nil {
+				errs = append(errs, fmt.Errorf("tlsCertFile: %w", err))
+			}
+		}
+		switch strings.ToLower(c.TLSClientAuth) {
+		case "none":
+		case "request", "require":
+			if c.TLSClientCAFile == "" {
+				errs = append(errs, fmt.Errorf("tlsClientCaFile must be set for client authentication"))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("tlsClientAuth %q is not one of none, request, require", c.TLSClientAuth))
+		}
+	}
+	ports := map[int]bool{c.Port: true}
+	if c.TLSEnabled {
+		ports[c.TLSPort] = true
+	}
+	for name, port := range map[string]int{
+		"tlsExpiredPort":       c.TLSExpiredPort,
+		"tlsWrongHostnamePort": c.TLSWrongHostnamePort,
+		"tlsSelfSignedPort":    c.TLSSelfSignedPort,
+		"tlsLegacyPort":        c.TLSLegacyPort,
+		"http2Port":            c.HTTP2Port,
+		"http2TlsPort":         c.HTTP2TLSPort,
+		"grpcPort":             c.GRPCPort,
+	} {
+		if port == 0 {
+			continue
+		}
+		if port < 0 || port > 65535 || ports[port] {
+			errs = append(errs, fmt.Errorf("%s %d is out of range or already used", name, port))
+		}
+		ports[port] = true
+	}
+	for _, entry := range c.Listeners {
+		l, err := ParseListener(entry)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if port := l.Port(); port != 0 {
+			if ports[port] {
+				errs = append(errs, fmt.Errorf("listeners entry %q uses a port that is already used", entry))
+			}
+			ports[port] = true
+		}
+	}
+	if c.HTTP2TLSPort != 0 && !c.TLSEnabled {
+		errs = append(errs, fmt.Errorf("http2TlsPort requires tlsEnabled"))
+	}
+	if c.ResponseCode < 100 || c.ResponseCode > 599 {
+		errs = append(errs, fmt.Errorf("responseCode %d is not a valid HTTP status code", c.ResponseCode))
+	}
+	for _, m := range c.MethodResponseCodeMapping {
+		method, code, found := strings.Cut(m, ":")
+		parsed, err := strconv.Atoi(strings.TrimSpace(code))
+		if !found || strings.TrimSpace(method) == "" || err != nil || parsed < 100 || parsed > 599 {
+			errs = append(errs, fmt.Errorf("methodResponseCodeMapping entry %q must look like METHOD:CODE", m))
+		}
+	}
+	switch strings.ToLower(c.LogLevel) {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("logLevel %q is not one of debug, info, warn, error", c.LogLevel))
+	}
+	switch strings.ToLower(c.LogFormat) {
+	case logging.FormatJSON, logging.FormatConsole:
+	default:
+		errs = append(errs, fmt.Errorf("logFormat %q is not one of json, console", c.LogFormat))
+	}
+	if c.RequestLogSampleRate < 1 {
+		errs = append(errs, fmt.Errorf("requestLogSampleRate must be at least 1"))
+	}
+	if c.RequestLogRateLimit < 0 {
+		errs = append(errs, fmt.Errorf("requestLogRateLimit must not be negative"))
+	}
+	for _, pattern := range slices.Concat(c.RequestLogIncludePaths, c.RequestLogExcludePaths) {
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), "/"); err != nil {
+			errs = append(errs, fmt.Errorf("request log path pattern %q is malformed", pattern))
+		}
+	}
+	switch strings.ToLower(c.RedactionMode) {
+	case "mask", "hash":
+	default:
+		errs = append(errs, fmt.Errorf("redactionMode %q is not one of mask, hash", c.RedactionMode))
+	}
+	if c.RequestLogBodyMaxSize < 0 {
+		errs = append(errs, fmt.Errorf("requestLogBodyMaxSize must not be negative"))
+	}
+	switch strings.ToLower(c.APIAuthMode) {
+	case "none":
+	case "bearer":
+		if c.APIAuthToken == "" {
+			errs = append(errs, fmt.Errorf("apiAuthToken must be set for bearer authentication"))
+		}
+	case "basic":
+		if c.APIAuthUsername == "" || c.APIAuthPassword == "" {
+			errs = append(errs, fmt.Errorf("apiAuthUsername and apiAuthPassword must be set for basic authentication"))
+		}
+	case "jwt":
+		if _, err := auth.LoadKeySet(c.APIAuthJwksFile); err != nil {
+			errs = append(errs, fmt.Errorf("apiAuthJwksFile: %w", err))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("apiAuthMode %q is not one of none, bearer, basic, jwt", c.APIAuthMode))
+	}
+	if c.EchoJwtEnabled {
+		if _, err := auth.LoadKeySet(c.EchoJwtJwksFile); err != nil {
+			errs = append(errs, fmt.Errorf("echoJwtJwksFile: %w", err))
+		}
+	}
+	if c.OAuthEnabled && c.OAuthSigningKeyFile != "" {
+		if _, err := auth.LoadSigningKey(c.OAuthSigningKeyFile); err != nil {
+			errs = append(errs, fmt.Errorf("oauthSigningKeyFile: %w", err))
+		}
+	}
+	if c.OAuthTokenExpiry <= 0 {
+		errs = append(errs, fmt.Errorf("oauthTokenExpiry must be positive"))
+	}
+	for _, client := range c.OAuthClients {
+		if id, _, found := strings.Cut(client, ":"); !found || id == "" {
+			errs = append(errs, fmt.Errorf("oauthClients entry %q must look like CLIENT_ID:SECRET", client))
+		}
+	}
+	for _, claim := range c.OAuthClaims {
+		if name, _, found := strings.Cut(claim, "="); !found || strings.TrimSpace(name) == "" {
+			errs = append(errs, fmt.Errorf("oauthClaims entry %q must look like NAME=VALUE", claim))
+		}
+	}
+	if c.SignatureEnabled {
+		if !slices.Contains(supportedSignatureAlgorithms, strings.ToLower(c.SignatureAlgorithm)) {
+			errs = append(errs, fmt.Errorf("signatureAlgorithm %q is not one of %s", c.SignatureAlgorithm, strings.Join(supportedSignatureAlgorithms, ", ")))
+		}
+		if c.SignatureHeader == "" || c.SignatureSecret == "" {
+			errs = append(errs, fmt.Errorf("signatureHeader and signatureSecret must be set for signature verification"))
+		}
+		if c.SignatureTimestampTolerance <= 0 {
+			errs = append(errs, fmt.Errorf("signatureTimestampTolerance must be positive"))
+		}
+		if c.SignatureFailureResponseCode < 100 || c.SignatureFailureResponseCode > 599 {
+			errs = append(errs, fmt.Errorf("signatureFailureResponseCode %d is not a valid HTTP status code", c.SignatureFailureResponseCode))
+		}
+	}
+	for _, p := range c.OTelPropagators {
+		if !slices.Contains(supportedPropagators, strings.ToLower(strings.TrimSpace(p))) {
+			errs = append(errs, fmt.Errorf("otelPropagators entry %q is not one of %s", p, strings.Join(supportedPropagators, ", ")))
+		}
+	}
+	if p := strings.ToLower(c.OTelExporterProtocol); p != "" && !slices.Contains(supportedExporterProtocols, p) {
+		errs = append(errs, fmt.Errorf("otelExporterProtocol %q is not one of %s", c.OTelExporterProtocol, strings.Join(supportedExporterProtocols, ", ")))
+	}
+	if strings.EqualFold(c.OTelExporterProtocol, "file") && c.OTelExporterFile == "" {
+		errs = append(errs, fmt.Errorf("otelExporterFile must be set for the file exporter"))
+	}
+	if !slices.Contains(supportedSamplers, strings.ToLower(c.OTelSampler)) {
+		errs = append(errs, fmt.Errorf("otelSampler %q is not one of %s", c.OTelSampler, strings.Join(supportedSamplers, ", ")))
+	}
+	if c.OTelSamplerRatio < 0 || c.OTelSamplerRatio > 1 {
+		errs = append(errs, fmt.Errorf("otelSamplerRatio must be between 0 and 1"))
+	}
+	if c.ReadBufferSize <= 0 || c.WriteBufferSize <= 0 || c.BodyLimit <= 0 || c.Concurrency <= 0 {
+		errs = append(errs, fmt.Errorf("readBufferSize, writeBufferSize, bodyLimit and concurrency must be positive"))
+	}
+	if c.ReadTimeout < 0 || c.WriteTimeout < 0 || c.IdleTimeout < 0 {
+		errs = append(errs, fmt.Errorf("readTimeout, writeTimeout and idleTimeout must not be negative"))
+	}
+	if c.SlowlorisDefaultDurationSeconds <= 0 || c.SlowlorisDefaultIntervalSeconds <= 0 {
+		errs = append(errs, fmt.Errorf("slowloris default duration and interval must be positive"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Settings returns the configuration as a map keyed like the configuration file,
+// e.g. for printing the effective configuration. Secrets are masked.
+func (c *configuration) Settings() (map[string]any, error) {
+	settings := make(map[string]any)
+	if err := mapstructure.Decode(c, &settings); err != nil {
+		return nil, err
+	}
+	for k, v := range settings {
+		// print durations the way they are written in the configuration file
+		if d, ok := v.(time.Duration); ok {
+			settings[k] = d.String()
+		}
+	}
+	for _, k := range secretSettings {
+		settings[k] = maskSetting(settings[k])
+	}
+	return settings, nil
+}
+
+// maskSetting masks a secret setting unless it is empty, so it is still visible
+// whether the secret is set. Of list entries like CLIENT_ID:SECRET only the
+// secret is masked.
+func maskSetting(v any) any {
+	switch value := v.(type) {
+	case string:
+		if value != "" {
+			return maskedSetting
+		}
+	case []string:
+		masked := make([]string, len(value))
+		for i, entry := range value {
+			id, _, _ := strings.Cut(entry, ":")
+			masked[i] = id + ":" + maskedSetting
+		}
+		return masked
+	}
+	return v
 }
 
 func (c *configuration) BuildMethodResponseCodeMap() {
@@ -84,25 +520,9 @@ func (c *configuration) BuildMethodResponseCodeMap() {
 		method := strings.ToUpper(strings.TrimSpace(parts[0]))
 		code, err := strconv.Atoi(strings.TrimSpace(parts[1]))
 		if err != nil {
-			log.Warnf("ignoring invalid method response code mapping: %s", m)
+			log.Warn().Str("mapping", m).Msg("ignoring invalid method response code mapping")
 			continue
 		}
 		c.MethodResponseCodeMap[method] = code
 	}
 }
-
-func parseLogLevel(lvl string) log.Level {
-	switch strings.ToLower(lvl) {
-	case "debug":
-		return log.LevelDebug
-	case "info":
-		return log.LevelInfo
-	case "warn":
-		return log.LevelWarn
-	case "error":
-		return log.LevelError
-	default:
-		log.Warnf("invalid log-level '%s', falling back to info", lvl)
-		return log.LevelInfo
-	}
-}
@@ -5,9 +5,13 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
@@ -24,6 +28,31 @@ func TestSetDefaults(t *testing.T) {
 	assert.Equal(t, []string{"x-request-key"}, viper.GetStringSlice("storeKeyRequestHeaders"))
 	assert.Equal(t, false, viper.GetBool("otelEnabled"))
 	assert.Equal(t, "cosmoparrot", viper.GetString("otelServiceName"))
+	assert.Equal(t, 4096, viper.GetInt("readBufferSize"))
+	assert.Equal(t, 4096, viper.GetInt("writeBufferSize"))
+	assert.Equal(t, 4*1024*1024, viper.GetInt("bodyLimit"))
+	assert.Equal(t, 256*1024, viper.GetInt("concurrency"))
+	assert.Equal(t, false, viper.GetBool("disableKeepalive"))
+}
+
+func TestServerTimeoutsFromEnvironment(t *testing.T) {
+	t.Setenv("COSMOPARROT_READTIMEOUT", "5s")
+	t.Setenv("COSMOPARROT_IDLETIMEOUT", "1m")
+
+	loadConfiguration()
+	defer func() {
+		os.Unsetenv("COSMOPARROT_READTIMEOUT")
+		os.Unsetenv("COSMOPARROT_IDLETIMEOUT")
+		loadConfiguration()
+	}()
+
+	assert.Equal(t, 5*time.Second, LoadedConfiguration.ReadTimeout)
+	assert.Equal(t, time.Minute, LoadedConfiguration.IdleTimeout)
+	assert.Equal(t, time.Duration(0), LoadedConfiguration.WriteTimeout)
+
+	settings, err := LoadedConfiguration.Settings()
+	assert.NoError(t, err)
+	assert.Equal(t, "5s", settings["readTimeout"])
 }
 
 func TestRequestLoggingEnvironmentOverride(t *testing.T) {
@@ -84,3 +113,106 @@ func TestConfigFileNotFound(t *testing.T) {
 	assert.Equal(t, 200, LoadedConfiguration.ResponseCode)
 	assert.Equal(t, []string{"x-request-key"}, LoadedConfiguration.StoreKeyRequestHeaders)
 }
+
+func TestLoadFromExplicitPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom.yml")
+	err := os.WriteFile(path, []byte("port: 7070\nresponseCode: 202\n"), 0o600)
+	assert.NoError(t, err)
+
+	assert.NoError(t, Load(path, nil))
+	defer loadConfiguration()
+
+	assert.Equal(t, 7070, LoadedConfiguration.Port)
+	assert.Equal(t, 202, LoadedConfiguration.ResponseCode)
+	assert.Equal(t, path, ConfigFileUsed())
+}
+
+func TestLoadFromMissingExplicitPath(t *testing.T) {
+	err := Load(filepath.Join(t.TempDir(), "missing.yml"), nil)
+	assert.Error(t, err)
+}
+
+func TestFlagsOverrideEnvironmentAndFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom.yml")
+	err := os.WriteFile(path, []byte("port: 7070\nresponseCode: 202\n"), 0o600)
+	assert.NoError(t, err)
+	t.Setenv("COSMOPARROT_RESPONSECODE", "203")
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	RegisterFlags(flags)
+	assert.NoError(t, flags.Parse([]string{"--port", "6060", "--store-key-request-headers", "x-a,x-b"}))
+
+	assert.NoError(t, Load(path, flags))
+	defer loadConfiguration()
+
+	assert.Equal(t, 6060, LoadedConfiguration.Port)
+	// unset flags must not shadow the environment
+	assert.Equal(t, 203, LoadedConfiguration.ResponseCode)
+	assert.Equal(t, []string{"x-a", "x-b"}, LoadedConfiguration.StoreKeyRequestHeaders)
+	assert.Equal(t, true, LoadedConfiguration.RequestLogging)
+}
+
+func TestConfigFlagsHaveDefaults(t *testing.T) {
+	viper.Reset()
+	setDefaults()
+	defer loadConfiguration()
+
+	for _, f := range configFlags {
+		assert.True(t, viper.IsSet(f.key), "flag %s overrides unknown key %s", f.name, f.key)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	viper.Reset()
+	setDefaults()
+	loadConfiguration()
+
+	valid := LoadedConfiguration
+	assert.NoError(t, valid.Validate())
+
+	invalid := LoadedConfiguration
+	invalid.Port = 0
+	invalid.ResponseCode = 42
+	invalid.MethodResponseCodeMapping = []string{"POST"}
+	invalid.LogLevel = "verbose"
+	invalid.APIAuthMode = "bearer"
+	invalid.TLSExpiredPort = 70000
+	invalid.Listeners = []string{":8443", ":8443=503"}
+
+	err := invalid.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "port 0")
+	assert.Contains(t, err.Error(), "responseCode 42")
+	assert.Contains(t, err.Error(), `"POST"`)
+	assert.Contains(t, err.Error(), `"verbose"`)
+	assert.Contains(t, err.Error(), "apiAuthToken")
+	assert.Contains(t, err.Error(), "tlsExpiredPort 70000")
+	assert.Contains(t, err.Error(), `listeners entry ":8443=503" uses a port`)
+}
+
+func TestSettingsMasksSecrets(t *testing.T) {
+	c := configuration{
+		RedactionMode:    "hash",
+		RedactionHashKey: "hunter2",
+		APIAuthToken:     "hunter2",
+		APIAuthUsername:  "admin",
+		APIAuthPassword:  "hunter2",
+		OAuthClients:     []string{"producer:hunter2", "other:hunter2"},
+		SignatureSecret:  "hunter2",
+	}
+	settings, err := c.Settings()
+	assert.NoError(t, err)
+	assert.Equal(t, maskedSetting, settings["redactionHashKey"])
+	assert.Equal(t, maskedSetting, settings["apiAuthToken"])
+	assert.Equal(t, maskedSetting, settings["apiAuthPassword"])
+	assert.Equal(t, []string{"producer:" + maskedSetting, "other:" + maskedSetting}, settings["oauthClients"])
+	assert.Equal(t, maskedSetting, settings["signatureSecret"])
+	assert.Equal(t, "admin", settings["apiAuthUsername"])
+	assert.Equal(t, "hash", settings["redactionMode"])
+	assert.NotContains(t, fmt.Sprint(settings), "hunter2")
+
+	// unset secrets stay empty
+	settings, err = (&configuration{}).Settings()
+	assert.NoError(t, err)
+	assert.Equal(t, "", settings["redactionHashKey"])
+}
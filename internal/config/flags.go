@@ -0,0 +1,147 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// configFlag is a command-line flag that overrides a configuration key.
+type configFlag struct {
+	name string
+	key  string
+	// value is the default of the flag and determines its type. Flags that are
+	// not set explicitly do not override the configuration, so it is a zero value.
+	value any
+	usage string
+}
+
+// configFlags defines the command-line flags. Both their registration and the
+// binding to the configuration keys are generated from it.
+var configFlags = []configFlag{
+	{"log-level", "logLevel", "", "log level (debug, info, warn, error)"},
+	{"log-format", "logFormat", "", "log format (json, console)"},
+	{"port", "port", 0, "port to listen on"},
+	{"tls-enabled", "tlsEnabled", false, "additionally listen for HTTPS"},
+	{"tls-port", "tlsPort", 0, "port to listen on for HTTPS"},
+	{"tls-cert-file", "tlsCertFile", "", "PEM certificate chain for HTTPS, a self-signed CA and certificate are generated if empty"},
+	{"tls-key-file", "tlsKeyFile", "", "PEM private key for HTTPS"},
+	{"tls-hostnames", "tlsHostnames", []string(nil), "host names and IP addresses of the generated certificate"},
+	{"tls-client-auth", "tlsClientAuth", "", "client certificate authentication (none, request, require)"},
+	{"tls-client-ca-file", "tlsClientCaFile", "", "PEM bundle of CAs client certificates are verified against"},
+	{"tls-expired-port", "tlsExpiredPort", 0, "port of a TLS listener presenting an expired certificate, 0 disables it"},
+	{"tls-wrong-hostname-port", "tlsWrongHostnamePort", 0, "port of a TLS listener presenting a certificate for another host name, 0 disables it"},
+	{"tls-self-signed-port", "tlsSelfSignedPort", 0, "port of a TLS listener presenting a self-signed certificate, 0 disables it"},
+	{"tls-legacy-port", "tlsLegacyPort", 0, "port of a TLS listener offering only TLS 1.0/1.1 and CBC or RC4 ciphers, 0 disables it"},
+	{"http2-port", "http2Port", 0, "port of a net/http listener accepting HTTP/1.1 and h2c, 0 disables it"},
+	{"http2-tls-port", "http2TlsPort", 0, "port of a net/http listener negotiating HTTP/2 via ALPN, 0 disables it"},
+	{"grpc-port", "grpcPort", 0, "port of the gRPC echo server, 0 disables it"},
+	{"listeners", "listeners", []string(nil), "additional listeners with optional default response code, e.g. :8081=503 or unix:/run/cosmoparrot.sock"},
+	{"response-code", "responseCode", 0, "HTTP response code returned by the echo handler"},
+	{"method-response-code-mapping", "methodResponseCodeMapping", []string(nil), "HTTP response code per method, e.g. POST:401"},
+	{"request-logging", "requestLogging", false, "log every incoming request"},
+	{"request-log-sample-rate", "requestLogSampleRate", 0, "log only every n-th request"},
+	{"request-log-rate-limit", "requestLogRateLimit", 0, "log at most n requests per second, 0 means unlimited"},
+	{"request-log-errors-only", "requestLogErrorsOnly", false, "log only requests answered with a non-2xx status"},
+	{"request-log-include-paths", "requestLogIncludePaths", []string(nil), "only log requests whose path matches one of these patterns"},
+	{"request-log-exclude-paths", "requestLogExcludePaths", []string(nil), "never log requests whose path matches one of these patterns"},
+	{"request-log-body", "requestLogBody", false, "include the request body in request logs"},
+	{"request-log-body-max-size", "requestLogBodyMaxSize", 0, "maximum number of body bytes included in request logs"},
+	{"redacted-headers", "redactedHeaders", []string(nil), "headers whose values are redacted in logs, echo responses and the request store"},
+	{"redaction-mode", "redactionMode", "", "how redacted values are replaced (mask, hash)"},
+	{"redaction-hash-key", "redactionHashKey", "", "key of the HMAC in hash redaction mode, random per process if empty"},
+	{"read-buffer-size", "readBufferSize", 0, "per-connection buffer size for reading requests, limits the header size"},
+	{"write-buffer-size", "writeBufferSize", 0, "per-connection buffer size for writing responses"},
+	{"body-limit", "bodyLimit", 0, "maximum request body size in bytes"},
+	{"read-timeout", "readTimeout", time.Duration(0), "maximum duration for reading a full request, 0 means unlimited"},
+	{"write-timeout", "writeTimeout", time.Duration(0), "maximum duration for writing a response, 0 means unlimited"},
+	{"idle-timeout", "idleTimeout", time.Duration(0), "maximum time to wait for the next request on keep-alive connections"},
+	{"concurrency", "concurrency", 0, "maximum number of concurrent connections"},
+	{"disable-keepalive", "disableKeepalive", false, "close connections after every response"},
+	{"store-key-request-headers", "storeKeyRequestHeaders", []string(nil), "request headers whose value is used as request store key"},
+	{"api-auth-mode", "apiAuthMode", "", "authentication of the admin and store APIs (none, bearer, basic, jwt)"},
+	{"api-auth-token", "apiAuthToken", "", "static token for bearer authentication"},
+	{"api-auth-username", "apiAuthUsername", "", "username for basic authentication"},
+	{"api-auth-password", "apiAuthPassword", "", "password for basic authentication"},
+	{"api-auth-jwks-file", "apiAuthJwksFile", "", "local JWKS file used to verify JWTs"},
+	{"api-auth-jwt-issuer", "apiAuthJwtIssuer", "", "required issuer of JWTs"},
+	{"api-auth-jwt-audience", "apiAuthJwtAudience", "", "required audience of JWTs"},
+	{"echo-jwt-enabled", "echoJwtEnabled", false, "require valid bearer JWTs on echo requests"},
+	{"echo-jwt-jwks-file", "echoJwtJwksFile", "", "local JWKS file used to verify echo request JWTs"},
+	{"echo-jwt-issuer", "echoJwtIssuer", "", "required issuer of echo request JWTs"},
+	{"echo-jwt-audience", "echoJwtAudience", "", "required audience of echo request JWTs"},
+	{"echo-jwt-required-scopes", "echoJwtRequiredScopes", []string(nil), "scopes echo request JWTs must grant"},
+	{"oauth-enabled", "oauthEnabled", false, "serve a mock OAuth2 token endpoint and its JWKS"},
+	{"oauth-signing-key-file", "oauthSigningKeyFile", "", "PEM private key signing issued tokens, generated if empty"},
+	{"oauth-issuer", "oauthIssuer", "", "issuer of issued tokens, defaults to the requested base URL"},
+	{"oauth-audience", "oauthAudience", "", "audience of issued tokens"},
+	{"oauth-token-expiry", "oauthTokenExpiry", time.Duration(0), "lifetime of issued tokens"},
+	{"oauth-clients", "oauthClients", []string(nil), "accepted clients as CLIENT_ID:SECRET, any client is accepted if empty"},
+	{"oauth-claims", "oauthClaims", []string(nil), "additional claims of issued tokens as NAME=VALUE"},
+	{"signature-enabled", "signatureEnabled", false, "verify HMAC signatures of echo requests"},
+	{"signature-algorithm", "signatureAlgorithm", "", "HMAC hash algorithm (sha1, sha256, sha384, sha512)"},
+	{"signature-header", "signatureHeader", "", "request header carrying the signature"},
+	{"signature-secret", "signatureSecret", "", "shared HMAC secret"},
+	{"signature-timestamp-header", "signatureTimestampHeader", "", "request header carrying the signed Unix timestamp"},
+	{"signature-timestamp-tolerance", "signatureTimestampTolerance", time.Duration(0), "maximum age of signature timestamps"},
+	{"signature-failure-response-code", "signatureFailureResponseCode", 0, "response code for requests with an invalid signature"},
+	{"metrics-enabled", "metricsEnabled", false, "expose Prometheus metrics on /metrics"},
+	{"otel-enabled", "otelEnabled", false, "enable OpenTelemetry tracing"},
+	{"otel-service-name", "otelServiceName", "", "service name reported in traces"},
+	{"otel-propagators", "otelPropagators", []string(nil), "trace context propagation formats (tracecontext, baggage, b3, b3multi, jaeger)"},
+	{"otel-exporter-protocol", "otelExporterProtocol", "", "exporter protocol (grpc, http/protobuf, stdout, file), defaults to OTEL_EXPORTER_OTLP_PROTOCOL"},
+	{"otel-exporter-file", "otelExporterFile", "", "file the file exporter appends to"},
+	{"otel-sampler", "otelSampler", "", "trace sampler, e.g. parentbased_traceidratio"},
+	{"otel-sampler-ratio", "otelSamplerRatio", 0.0, "sampling ratio for the ratio based samplers"},
+	{"otel-metrics-enabled", "otelMetricsEnabled", false, "additionally export metrics via OTLP when OpenTelemetry is enabled"},
+	{"otel-logs-enabled", "otelLogsEnabled", false, "additionally export logs via OTLP when OpenTelemetry is enabled"},
+	{"slowloris-default-duration-seconds", "slowlorisDefaultDurationSeconds", 0, "default duration of slowloris responses"},
+	{"slowloris-default-interval-seconds", "slowlorisDefaultIntervalSeconds", 0, "default interval between slowloris writes"},
+}
+
+// RegisterFlags defines the configuration flags on fs. Flags that are not set
+// explicitly fall back to the environment, the configuration file and the defaults.
+func RegisterFlags(fs *pflag.FlagSet) {
+	for _, f := range configFlags {
+		switch value := f.value.(type) {
+		case string:
+			fs.String(f.name, value, f.usage)
+		case int:
+			fs.Int(f.name, value, f.usage)
+		case bool:
+			fs.Bool(f.name, value, f.usage)
+		case float64:
+			fs.Float64(f.name, value, f.usage)
+		case time.Duration:
+			fs.Duration(f.name, value, f.usage)
+		case []string:
+			fs.StringSlice(f.name, value, f.usage)
+		default:
+			panic(fmt.Sprintf("flag %s has unsupported type %T", f.name, f.value))
+		}
+	}
+}
+
+func bindFlags(fs *pflag.FlagSet) error {
+	if fs == nil {
+		return nil
+	}
+
+	for _, f := range configFlags {
+		flag := fs.Lookup(f.name)
+		if flag == nil {
+			continue
+		}
+		if err := viper.BindPFlag(f.key, flag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
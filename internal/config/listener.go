@@ -0,0 +1,69 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Listener is an additional listener from the listeners option.
+type Listener struct {
+	// Network is "tcp" or "unix"
+	Network string
+	// Address is a host and port or a socket path
+	Address string
+	// ResponseCode replaces responseCode for requests on the listener, 0 keeps it
+	ResponseCode int
+}
+
+// ParseListener parses a listeners entry like ":8081", "127.0.0.1:8081=503"
+// or "unix:/run/cosmoparrot.sock=404".
+func ParseListener(entry string) (Listener, error) {
+	l := Listener{Network: "tcp", Address: strings.TrimSpace(entry)}
+	if i := strings.LastIndex(l.Address, "="); i >= 0 {
+		code, err := strconv.Atoi(strings.TrimSpace(l.Address[i+1:]))
+		if err != nil || code < 100 || code > 599 {
+			return Listener{}, fmt.Errorf("listeners entry %q has no valid HTTP status code", entry)
+		}
+		l.Address, l.ResponseCode = strings.TrimSpace(l.Address[:i]), code
+	}
+
+	if path, found := strings.CutPrefix(l.Address, "unix:"); found {
+		if path == "" {
+			return Listener{}, fmt.Errorf("listeners entry %q has no socket path", entry)
+		}
+		l.Network, l.Address = "unix", path
+		return l, nil
+	}
+	_, port, err := net.SplitHostPort(l.Address)
+	if err != nil {
+		return Listener{}, fmt.Errorf("listeners entry %q must look like [HOST]:PORT[=CODE] or unix:PATH[=CODE]", entry)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return Listener{}, fmt.Errorf("listeners entry %q has no valid port", entry)
+	}
+	return l, nil
+}
+
+// Port returns the port of a TCP listener, 0 for unix sockets.
+func (l Listener) Port() int {
+	if l.Network != "tcp" {
+		return 0
+	}
+	_, port, _ := net.SplitHostPort(l.Address)
+	n, _ := strconv.Atoi(port)
+	return n
+}
+
+// String returns the address the way it is configured, without the response code.
+func (l Listener) String() string {
+	if l.Network == "unix" {
+		return "unix:" + l.Address
+	}
+	return l.Address
+}
@@ -0,0 +1,43 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListener(t *testing.T) {
+	for entry, expected := range map[string]Listener{
+		":8081":                       {Network: "tcp", Address: ":8081"},
+		"127.0.0.1:8082=503":          {Network: "tcp", Address: "127.0.0.1:8082", ResponseCode: 503},
+		"[::1]:8083 = 404":            {Network: "tcp", Address: "[::1]:8083", ResponseCode: 404},
+		"unix:/run/cosmoparrot.sock":  {Network: "unix", Address: "/run/cosmoparrot.sock"},
+		"unix:/tmp/consumer.sock=418": {Network: "unix", Address: "/tmp/consumer.sock", ResponseCode: 418},
+	} {
+		l, err := ParseListener(entry)
+		require.NoError(t, err, entry)
+		assert.Equal(t, expected, l, entry)
+	}
+
+	for _, entry := range []string{"8081", ":0", ":70000", ":8081=999", ":8081=abc", "unix:", "unix:=200"} {
+		_, err := ParseListener(entry)
+		assert.Error(t, err, entry)
+	}
+}
+
+func TestListener_String(t *testing.T) {
+	l, err := ParseListener("unix:/tmp/consumer.sock=418")
+	require.NoError(t, err)
+	assert.Equal(t, "unix:/tmp/consumer.sock", l.String())
+	assert.Equal(t, 0, l.Port())
+
+	l, err = ParseListener("127.0.0.1:8082=503")
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:8082", l.String())
+	assert.Equal(t, 8082, l.Port())
+}
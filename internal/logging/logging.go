@@ -0,0 +1,51 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package logging sets up the global zerolog logger that all packages log through.
+package logging
+
+import (
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+var output io.Writer = os.Stderr
+
+func init() {
+	zerolog.TimeFieldFormat = time.RFC3339Nano
+}
+
+// Configure applies the log level and format ("json" or "console") to the
+// global logger. Invalid levels fall back to info.
+func Configure(level, format string) {
+	if strings.EqualFold(format, FormatConsole) {
+		output = zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339}
+	} else {
+		output = os.Stderr
+	}
+	log.Logger = zerolog.New(output).With().Timestamp().Logger()
+
+	parsed, err := zerolog.ParseLevel(strings.ToLower(level))
+	if err != nil || parsed == zerolog.NoLevel {
+		log.Warn().Str("logLevel", level).Msg("invalid log-level, falling back to info")
+		parsed = zerolog.InfoLevel
+	}
+	zerolog.SetGlobalLevel(parsed)
+}
+
+// Output returns the writer the global logger writes to, e.g. for adding
+// further destinations.
+func Output() io.Writer {
+	return output
+}
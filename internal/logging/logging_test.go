@@ -0,0 +1,36 @@
+// Copyright 2026 Deutsche Telekom IT GmbH
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package logging
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigure_Level(t *testing.T) {
+	defer zerolog.SetGlobalLevel(zerolog.InfoLevel)
+
+	Configure("debug", FormatJSON)
+	assert.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+
+	Configure("WARN", FormatJSON)
+	assert.Equal(t, zerolog.WarnLevel, zerolog.GlobalLevel())
+
+	Configure("verbose", FormatJSON)
+	assert.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+}
+
+func TestConfigure_Format(t *testing.T) {
+	defer Configure("info", FormatJSON)
+
+	Configure("info", FormatJSON)
+	assert.Equal(t, os.Stderr, Output())
+
+	Configure("info", FormatConsole)
+	assert.IsType(t, zerolog.ConsoleWriter{}, Output())
+}
@@ -12,7 +12,7 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/gofiber/fiber/v2/log"
+	"github.com/rs/zerolog/log"
 )
 
 const (
@@ -38,7 +38,10 @@ func Configure() {
 
 	soft := int64(float64(limit) * reserveRatio)
 	debug.SetMemoryLimit(soft)
-	log.Infof("GOMEMLIMIT set to %d bytes (%.0f%% of cgroup limit %d bytes)", soft, reserveRatio*100, limit)
+	log.Info().
+		Int64("limit", soft).
+		Int64("cgroupLimit", limit).
+		Msgf("GOMEMLIMIT set to %.0f%% of cgroup limit", reserveRatio*100)
 }
 
 func detectCgroupLimit() (int64, bool) {
@@ -5,16 +5,22 @@
 package main
 
 import (
-	"cosmoparrot/internal/api"
-	"cosmoparrot/internal/memlimit"
+	"cosmoparrot/internal/cmd"
 	"embed"
 	_ "embed"
+	"fmt"
+	"os"
 )
 
 //go:embed web/*
 var webDir embed.FS
 
+// version is set at build time via -ldflags "-X main.version=<version>".
+var version = "dev"
+
 func main() {
-	memlimit.Configure()
-	api.Listen(webDir)
+	if err := cmd.NewRootCommand(webDir, version).Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
 }